@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/code-gorilla-au/goety/internal/dynamodb"
+	"github.com/code-gorilla-au/goety/internal/emitter"
+	"github.com/code-gorilla-au/goety/internal/goety"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/goety/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagCopySourceTable      string
+	flagCopySourceEndpoint   string
+	flagCopySourceRegion     string
+	flagCopySourceProfile    string
+	flagCopyTargetTable      string
+	flagCopyTargetEndpoint   string
+	flagCopyTargetRegion     string
+	flagCopyTargetProfile    string
+	flagCopyExtractAttrs     []string
+	flagCopyLimit            int32
+	flagCopyFilterExp        string
+	flagCopyFilterAttrName   string
+	flagCopyFilterAttrValue  string
+	flagCopyFilterValuesJSON string
+	flagCopyWhere            string
+	flagCopyMaxRCU           string
+	flagCopyMaxWCU           string
+	flagCopyKeyCondition     string
+	flagCopyIndex            string
+	flagCopySegments         int32
+	flagCopyTransform        string
+	flagCopyMaxAttempts      int
+	flagCopyRetryBase        time.Duration
+	flagCopyRetryMax         time.Duration
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy --source-table [TABLE_NAME] --target-table [TABLE_NAME]",
+	Short: "copy the contents of a dynamodb table to another table",
+	Long:  "copy will scan all items within a dynamodb table, or query them with a key condition, and write them to another table",
+	Run:   copyFunc,
+}
+
+func init() {
+	copyCmd.Flags().StringVar(&flagCopySourceTable, "source-table", "", "Source table name")
+	copyCmd.Flags().StringVar(&flagCopySourceEndpoint, "source-endpoint", "", "DynamoDB endpoint of the source table, if none is provide it will use the default aws endpoint")
+	copyCmd.Flags().StringVar(&flagCopySourceRegion, "source-region", "", "aws region the source table is located, defaults to the aws region flag")
+	copyCmd.Flags().StringVar(&flagCopySourceProfile, "source-profile", "", "Optional aws profile used to read the source table")
+	copyCmd.Flags().StringVar(&flagCopyTargetTable, "target-table", "", "Target table name")
+	copyCmd.Flags().StringVar(&flagCopyTargetEndpoint, "target-endpoint", "", "DynamoDB endpoint of the target table, if none is provide it will use the default aws endpoint")
+	copyCmd.Flags().StringVar(&flagCopyTargetRegion, "target-region", "", "aws region the target table is located, defaults to the aws region flag")
+	copyCmd.Flags().StringVar(&flagCopyTargetProfile, "target-profile", "", "Optional aws profile used to write the target table")
+	copyCmd.Flags().StringSliceVarP(&flagCopyExtractAttrs, "attributes", "a", []string{}, "Optionally specify a list of attributes to copy from the source table")
+	copyCmd.Flags().Int32VarP(&flagCopyLimit, "limit", "l", 0, "Limit the number of items returned per scan iteration")
+	copyCmd.Flags().StringVarP(&flagCopyFilterExp, "filter", "f", "", "Filter expression to apply to the scan operation")
+	copyCmd.Flags().StringVarP(&flagCopyFilterAttrName, "attribute-name", "N", "", "Filter expression attribute names")
+	copyCmd.Flags().StringVarP(&flagCopyFilterAttrValue, "attribute-value", "V", "", "Filter expression attribute values, :name=TYPE:value where the type is optional e.g. \":tenant=tenant-1,:age=N:30,:active=BOOL:true\"")
+	copyCmd.Flags().StringVar(&flagCopyFilterValuesJSON, "attribute-values-json", "", "Filter expression attribute values as a dynamodb json object e.g. '{\":age\": {\"N\": \"30\"}}'")
+	copyCmd.Flags().StringVar(&flagCopyWhere, "where", "", "Where condition compiled into a filter expression, names and values are escaped e.g. 'status = \"active\" and created > 2024-01-01'")
+	copyCmd.Flags().StringVarP(&flagCopyKeyCondition, "key-condition", "k", "", "Key condition expression, the source table or index is queried instead of scanned")
+	copyCmd.Flags().StringVarP(&flagCopyIndex, "index", "i", "", "Optional global or local secondary index to read from")
+	copyCmd.Flags().Int32VarP(&flagCopySegments, "segments", "S", 1, "Number of segments to scan the source table with in parallel")
+	copyCmd.Flags().StringVar(&flagCopyTransform, "transform", "", "Optional json file of transform rules applied to each item before it is written to the target table")
+	copyCmd.Flags().StringVar(&flagCopyMaxRCU, "max-rcu", "", "Limit the read capacity units consumed per second on the source table, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
+	copyCmd.Flags().StringVar(&flagCopyMaxWCU, "max-wcu", "", "Limit the write capacity units consumed per second on the target table, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
+	copyCmd.Flags().IntVar(&flagCopyMaxAttempts, "max-attempts", dynamodb.DefaultRetryPolicy.MaxAttempts, "Maximum number of requests made for a batch write to the target table, including the first request, before unprocessed items are an error")
+	copyCmd.Flags().DurationVar(&flagCopyRetryBase, "retry-base-delay", dynamodb.DefaultRetryPolicy.BaseDelay, "Delay before the first retry of unprocessed items, doubled for each retry with full jitter")
+	copyCmd.Flags().DurationVar(&flagCopyRetryMax, "retry-max-delay", dynamodb.DefaultRetryPolicy.MaxDelay, "Maximum delay between retries of unprocessed items")
+}
+
+// copyFunc is the entry point for the copy command. It will copy the items of a dynamodb table to another table
+func copyFunc(cmd *cobra.Command, args []string) {
+	log := logging.New(flagRootVerbose)
+	ctx := context.Background()
+
+	if err := parseCopyFlag(); err != nil {
+		log.Error("error parsing flags", "error", err)
+		os.Exit(1)
+	}
+
+	log.Debug("loading dynamodb clients")
+	sourceClient, err := dynamodb.NewProfileClient(ctx, copyRegion(flagCopySourceRegion), flagCopySourceEndpoint, flagCopySourceProfile)
+	if err != nil {
+		log.Error("could not load source client")
+		os.Exit(1)
+	}
+
+	targetClient, err := dynamodb.NewProfileClient(ctx, copyRegion(flagCopyTargetRegion), flagCopyTargetEndpoint, flagCopyTargetProfile)
+	if err != nil {
+		log.Error("could not load target client")
+		os.Exit(1)
+	}
+
+	where, err := parseWhere(flagCopyWhere)
+	if err != nil {
+		log.Error("error parsing where condition", "error", err)
+		os.Exit(1)
+	}
+
+	filterValues, err := parseFilterValues(flagCopyFilterAttrValue, flagCopyFilterValuesJSON)
+	if err != nil {
+		log.Error("error parsing filter values", "error", err)
+		os.Exit(1)
+	}
+
+	if err = limitCapacity(ctx, sourceClient, flagCopySourceTable, flagCopyMaxRCU, ""); err != nil {
+		log.Error("could not limit source capacity", "error", err)
+		os.Exit(1)
+	}
+
+	if err = limitCapacity(ctx, targetClient, flagCopyTargetTable, "", flagCopyMaxWCU); err != nil {
+		log.Error("could not limit target capacity", "error", err)
+		os.Exit(1)
+	}
+
+	targetClient.SetRetryPolicy(dynamodb.RetryPolicy{
+		MaxAttempts: flagCopyMaxAttempts,
+		BaseDelay:   flagCopyRetryBase,
+		MaxDelay:    flagCopyRetryMax,
+	})
+
+	transform, err := loadTransform(flagCopyTransform)
+	if err != nil {
+		log.Error("error loading transform rules", "error", err)
+		os.Exit(1)
+	}
+
+	msgEmitter := emitter.New()
+
+	goetyService := goety.New(sourceClient, log, msgEmitter, flagRootDryRun)
+
+	if !flagRootVerbose {
+		spin := spinner.New(msgEmitter)
+		spin.Start("starting copy")
+		defer spin.Stop("")
+	}
+
+	if err = goetyService.Copy(
+		ctx,
+		flagCopySourceTable,
+		targetClient,
+		flagCopyTargetTable,
+		goety.WithAttrs(flagCopyExtractAttrs),
+		goety.WithLimit(flagCopyLimit),
+		goety.WithKeyCondition(flagCopyKeyCondition),
+		goety.WithIndex(flagCopyIndex),
+		goety.WithFilterExpression(flagCopyFilterExp),
+		goety.WithFilterNameAttrs(flagCopyFilterAttrName),
+		goety.WithFilterValues(filterValues),
+		goety.WithWhere(where),
+		goety.WithSegments(flagCopySegments),
+		goety.WithTransform(transform),
+	); err != nil {
+		log.Error("error copying table", "error", err)
+		os.Exit(1)
+	}
+}
+
+// parseCopyFlag will validate the flags passed to the copy command
+func parseCopyFlag() error {
+	if flagCopySourceTable == "" {
+		return errors.New("source table name is required")
+	}
+	if flagCopyTargetTable == "" {
+		return errors.New("target table name is required")
+	}
+	if flagCopySegments < 1 {
+		return errors.New("segments must be at least 1")
+	}
+	if flagCopyKeyCondition != "" && flagCopySegments > 1 {
+		return errors.New("segments cannot be used with a key condition")
+	}
+	if flagCopySourceTable == flagCopyTargetTable &&
+		flagCopySourceEndpoint == flagCopyTargetEndpoint &&
+		copyRegion(flagCopySourceRegion) == copyRegion(flagCopyTargetRegion) &&
+		flagCopySourceProfile == flagCopyTargetProfile {
+		return errors.New("source and target table must be different")
+	}
+	if err := validateRetry(flagCopyMaxAttempts, flagCopyRetryBase, flagCopyRetryMax); err != nil {
+		return err
+	}
+	return nil
+}
+
+// copyRegion - returns the region for one side of the copy, defaulting to the aws region flag
+func copyRegion(region string) string {
+	if region != "" {
+		return region
+	}
+
+	return flagRootAwsRegion
+}
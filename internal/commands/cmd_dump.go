@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"fmt"
+	"io"
 	"os"
 
 	"github.com/code-gorilla-au/goety/internal/dynamodb"
@@ -16,21 +16,35 @@ import (
 )
 
 var (
-	flagDumpTableName       string
-	flagDumpEndpoint        string
-	flagDumpFilePath        string
-	flagDumpExtractAttrs    []string
-	flagDumpLimit           int32
-	flagDumpFilterExp       string
-	flagDumpFilterAttrName  string
-	flagDumpFilterAttrValue string
-	flagDumpRawOutput       bool
+	flagDumpTableName        string
+	flagDumpEndpoint         string
+	flagDumpFilePath         string
+	flagDumpExtractAttrs     []string
+	flagDumpLimit            int32
+	flagDumpFilterExp        string
+	flagDumpFilterAttrName   string
+	flagDumpFilterAttrValue  string
+	flagDumpFilterValuesJSON string
+	flagDumpWhere            string
+	flagDumpMaxRCU           string
+	flagDumpRawOutput        bool
+	flagDumpSegments         int32
+	flagDumpNumberMode       string
+	flagDumpKeyCondition     string
+	flagDumpIndex            string
+	flagDumpCheckpoint       string
+	flagDumpResume           bool
+	flagDumpFormat           string
+	flagDumpCompress         string
+	flagDumpCSVNested        string
+	flagDumpTransform        string
+	flagDumpMaskConfig       string
 )
 
 var dumpCmd = &cobra.Command{
 	Use:   "dump -t [TABLE_NAME] -p [FILE_PATH]",
 	Short: "dump the contents of a dynamodb to a file",
-	Long:  "dump will scan all items within a dynamodb table and write the contents to a file",
+	Long:  "dump will scan all items within a dynamodb table, or query them with a key condition, and write the contents to a file",
 	Run:   dumpFunc,
 }
 
@@ -42,8 +56,22 @@ func init() {
 	dumpCmd.Flags().Int32VarP(&flagDumpLimit, "limit", "l", 0, "Limit the number of items returned per scan iteration")
 	dumpCmd.Flags().StringVarP(&flagDumpFilterExp, "filter", "f", "", "Filter expression to apply to the scan operation")
 	dumpCmd.Flags().StringVarP(&flagDumpFilterAttrName, "attribute-name", "N", "", "Filter expression attribute names")
-	dumpCmd.Flags().StringVarP(&flagDumpFilterAttrValue, "attribute-value", "V", "", "Filter expression attribute values")
+	dumpCmd.Flags().StringVarP(&flagDumpFilterAttrValue, "attribute-value", "V", "", "Filter expression attribute values, :name=TYPE:value where the type is optional e.g. \":tenant=tenant-1,:age=N:30,:active=BOOL:true\"")
+	dumpCmd.Flags().StringVar(&flagDumpFilterValuesJSON, "attribute-values-json", "", "Filter expression attribute values as a dynamodb json object e.g. '{\":age\": {\"N\": \"30\"}}'")
+	dumpCmd.Flags().StringVar(&flagDumpWhere, "where", "", "Where condition compiled into a filter expression, names and values are escaped e.g. 'status = \"active\" and created > 2024-01-01'")
 	dumpCmd.Flags().BoolVarP(&flagDumpRawOutput, "raw-output", "R", false, "Optional flag to output the dynamodb scan without transformation")
+	dumpCmd.Flags().Int32VarP(&flagDumpSegments, "segments", "S", 1, "Number of segments to scan the table with in parallel")
+	dumpCmd.Flags().StringVarP(&flagDumpKeyCondition, "key-condition", "k", "", "Key condition expression, the table or index is queried instead of scanned")
+	dumpCmd.Flags().StringVarP(&flagDumpIndex, "index", "i", "", "Optional global or local secondary index to read from")
+	dumpCmd.Flags().StringVarP(&flagDumpCheckpoint, "checkpoint", "c", "", "Optional file to save progress to, so an interrupted dump can be resumed")
+	dumpCmd.Flags().BoolVar(&flagDumpResume, "resume", false, "Resume the dump from the checkpoint file, appending to the existing output file")
+	dumpCmd.Flags().StringVar(&flagDumpFormat, "format", "", "Output format: json, jsonl, csv or parquet. Defaults to the file extension, or json")
+	dumpCmd.Flags().StringVar(&flagDumpCSVNested, "csv-nested", string(goety.CSVNestedJSON), "How nested values are written to csv: json cells, or dotted columns")
+	dumpCmd.Flags().StringVar(&flagDumpCompress, "compress", "", "Compress the output: none, gzip or zstd. Defaults to the file extension (.gz or .zst), or none")
+	dumpCmd.Flags().StringVar(&flagDumpTransform, "transform", "", "Optional json file of transform rules applied to each item before it is written")
+	dumpCmd.Flags().StringVar(&flagDumpMaskConfig, "mask-config", "", "Optional yaml file of masks applied to attributes of each item e.g. redact, hash, fake_email, fake_name or truncate")
+	dumpCmd.Flags().StringVar(&flagDumpNumberMode, "number-mode", string(dynamodb.NumberModeExact), "How numbers are written when flattening items: float, string or exact")
+	dumpCmd.Flags().StringVar(&flagDumpMaxRCU, "max-rcu", "", "Limit the read capacity units consumed per second, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
 }
 
 func dumpFunc(cmd *cobra.Command, args []string) {
@@ -62,41 +90,134 @@ func dumpFunc(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if err = limitCapacity(ctx, dbClient, flagDumpTableName, flagDumpMaxRCU, ""); err != nil {
+		log.Error("could not limit capacity", "error", err)
+		os.Exit(1)
+	}
+
 	msgEmitter := emitter.New()
 
+	var checkpoint goety.CheckpointStore
+	if flagDumpCheckpoint != "" {
+		checkpoint = goety.NewFileCheckpoint(flagDumpCheckpoint)
+	}
+
+	where, err := parseWhere(flagDumpWhere)
+	if err != nil {
+		log.Error("error parsing where condition", "error", err)
+		os.Exit(1)
+	}
+
+	filterValues, err := parseFilterValues(flagDumpFilterAttrValue, flagDumpFilterValuesJSON)
+	if err != nil {
+		log.Error("error parsing filter values", "error", err)
+		os.Exit(1)
+	}
+
+	transform, err := loadTransform(flagDumpTransform)
+	if err != nil {
+		log.Error("error loading transform rules", "error", err)
+		os.Exit(1)
+	}
+
+	var mask *goety.MaskConfig
+	if flagDumpMaskConfig != "" {
+		mask, err = goety.LoadMaskConfig(flagDumpMaskConfig)
+		if err != nil {
+			log.Error("error loading mask config", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	var writer goety.Writer
+	var file *os.File
+	var compressed *goety.CompressedWriter
 	if flagRootDryRun {
 		log.Info("dry run enabled, no file will be created")
 		writer = &bytes.Buffer{}
 	} else {
-		file, err := os.Create(flagDumpFilePath)
+		file, err = openDumpFile(flagDumpFilePath, checkpoint, flagDumpResume)
 		if err != nil {
-			fmt.Println("Error creating file:", err)
-			return
+			log.Error("error creating file", "error", err)
+			os.Exit(1)
 		}
-		defer file.Close()
 		writer = file
+
+		if compression := dumpCompression(); compression != goety.CompressionNone {
+			compressed, err = goety.NewCompressedWriter(file, compression)
+			if err != nil {
+				_ = file.Close()
+				log.Error("error creating file", "error", err)
+				os.Exit(1)
+			}
+			writer = compressed
+		}
 	}
 
 	g := goety.New(dbClient, log, msgEmitter, flagRootDryRun)
 
+	var spin *spinner.Spinner
 	if !flagRootVerbose {
-		spin := spinner.New(msgEmitter)
+		spin = spinner.New(msgEmitter)
 		spin.Start("starting dump")
-		defer spin.Stop("dump complete")
 	}
-	_ = g.Dump(
+
+	err = g.Dump(
 		ctx,
 		flagDumpTableName,
 		writer,
 		goety.WithAttrs(flagDumpExtractAttrs),
 		goety.WithLimit(flagDumpLimit),
+		goety.WithKeyCondition(flagDumpKeyCondition),
+		goety.WithIndex(flagDumpIndex),
 		goety.WithFilterExpression(flagDumpFilterExp),
 		goety.WithFilterNameAttrs(flagDumpFilterAttrName),
-		goety.WithFilterNameValues(flagDumpFilterAttrValue),
+		goety.WithFilterValues(filterValues),
+		goety.WithWhere(where),
 		goety.WithRawOutput(flagDumpRawOutput),
+		goety.WithSegments(flagDumpSegments),
+		goety.WithNumberMode(dynamodb.NumberMode(flagDumpNumberMode)),
+		goety.WithCheckpoint(checkpoint),
+		goety.WithResume(flagDumpResume),
+		goety.WithFormat(dumpFormat()),
+		goety.WithCSVNested(goety.CSVNested(flagDumpCSVNested)),
+		goety.WithTransform(transform),
+		goety.WithMask(mask),
 	)
 
+	closeErr := closeDumpFile(compressed, file)
+
+	if spin != nil {
+		message := "dump complete"
+		if err != nil || closeErr != nil {
+			message = ""
+		}
+		spin.Stop(message)
+	}
+
+	if err != nil {
+		log.Error("error dumping table", "error", err)
+		os.Exit(1)
+	}
+
+	if closeErr != nil {
+		log.Error("error writing file", "error", closeErr)
+		os.Exit(1)
+	}
+}
+
+// closeDumpFile - closes the compressed writer, writing the end of the compressed stream, and then the file.
+// Either may be nil, as a dry run has no file and an uncompressed dump has no compressed writer.
+func closeDumpFile(compressed *goety.CompressedWriter, file *os.File) error {
+	var errs []error
+	if compressed != nil {
+		errs = append(errs, compressed.Close())
+	}
+	if file != nil {
+		errs = append(errs, file.Close())
+	}
+
+	return errors.Join(errs...)
 }
 
 // parsePurgeFlag will validate the flags passed to the purge command
@@ -107,5 +228,96 @@ func parseDumpFlag() error {
 	if flagDumpFilePath == "" {
 		return errors.New("file path is required")
 	}
+	if flagDumpSegments < 1 {
+		return errors.New("segments must be at least 1")
+	}
+	if flagDumpResume && flagDumpCheckpoint == "" {
+		return errors.New("checkpoint is required to resume")
+	}
+	if flagDumpKeyCondition != "" && flagDumpSegments > 1 {
+		return errors.New("segments cannot be used with a key condition")
+	}
+	if _, err := dynamodb.ParseNumberMode(flagDumpNumberMode); err != nil {
+		return err
+	}
+	if flagDumpFormat != "" {
+		if _, err := goety.ParseFormat(flagDumpFormat); err != nil {
+			return err
+		}
+	}
+	if _, err := goety.ParseCSVNested(flagDumpCSVNested); err != nil {
+		return err
+	}
+	switch dumpFormat() {
+	case goety.FormatCSV:
+		if flagDumpRawOutput {
+			return errors.New("raw output cannot be written as csv")
+		}
+		if flagDumpCheckpoint != "" && len(flagDumpExtractAttrs) == 0 {
+			return errors.New("attributes are required to checkpoint csv output")
+		}
+	case goety.FormatParquet:
+		if flagDumpRawOutput {
+			return errors.New("raw output cannot be written as parquet")
+		}
+		if flagDumpCheckpoint != "" {
+			return errors.New("checkpoint is not supported for parquet output")
+		}
+	}
+	if flagDumpCompress != "" {
+		if _, err := goety.ParseCompression(flagDumpCompress); err != nil {
+			return err
+		}
+	}
+	if flagDumpResume && dumpCompression() != goety.CompressionNone {
+		return errors.New("resume is not supported for compressed output")
+	}
 	return nil
 }
+
+// dumpCompression - returns the compress flag, or the compression implied by the file extension
+func dumpCompression() goety.Compression {
+	if flagDumpCompress != "" {
+		return goety.Compression(flagDumpCompress)
+	}
+
+	return goety.CompressionFromPath(flagDumpFilePath)
+}
+
+// dumpFormat - returns the format flag, or the format implied by the file extension
+func dumpFormat() goety.Format {
+	if flagDumpFormat != "" {
+		return goety.Format(flagDumpFormat)
+	}
+
+	return goety.FormatFromPath(flagDumpFilePath)
+}
+
+// openDumpFile - creates the dump file, or when resuming, truncates the existing file to the last checkpoint
+func openDumpFile(path string, checkpoint goety.CheckpointStore, resume bool) (*os.File, error) {
+	if !resume {
+		return os.Create(path)
+	}
+
+	progress, err := checkpoint.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = file.Truncate(progress.Offset); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if _, err = file.Seek(progress.Offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"os"
+	"time"
 
 	"github.com/code-gorilla-au/goety/internal/dynamodb"
 	"github.com/code-gorilla-au/goety/internal/emitter"
@@ -14,24 +15,54 @@ import (
 )
 
 var (
-	flagPurgeTableName    string
-	flagPurgeEndpoint     string
-	flagPurgePartitionKey string
-	flagPurgeSortKey      string
+	flagPurgeTableName        string
+	flagPurgeEndpoint         string
+	flagPurgePartitionKey     string
+	flagPurgeSortKey          string
+	flagPurgeSegments         int32
+	flagPurgeCheckpoint       string
+	flagPurgeResume           bool
+	flagPurgeFilterExp        string
+	flagPurgeFilterName       string
+	flagPurgeFilterValue      string
+	flagPurgeFilterValuesJSON string
+	flagPurgeWhere            string
+	flagPurgePartition        string
+	flagPurgeSortCond         string
+	flagPurgeMaxRCU           string
+	flagPurgeMaxWCU           string
+	flagPurgeMaxAttempts      int
+	flagPurgeRetryBase        time.Duration
+	flagPurgeRetryMax         time.Duration
 )
 
 var purgeCmd = &cobra.Command{
-	Use:   "purge -t [TABLE_NAME] -p [PARTITION_KEY] -s [SORT_KEY]",
+	Use:   "purge -t [TABLE_NAME]",
 	Short: "purge a dynamodb table of all items",
-	Long:  "purge will scan all items within a dynamodb table and use a batch delete to remove all records",
+	Long:  "purge will scan all items within a dynamodb table, or query a single partition, optionally only the items matching a filter, and use a batch delete to remove the records",
 	Run:   purgeFunc,
 }
 
 func init() {
 	purgeCmd.Flags().StringVarP(&flagPurgeTableName, "table", "t", "", "table name")
 	purgeCmd.Flags().StringVarP(&flagPurgeEndpoint, "endpoint", "e", "", "DynamoDB endpoint to connect to, if none is provide it will use the default aws endpoint")
-	purgeCmd.Flags().StringVarP(&flagPurgePartitionKey, "partition-key", "p", "pk", "The name of the partition key")
-	purgeCmd.Flags().StringVarP(&flagPurgeSortKey, "sort-key", "s", "sk", "The name of the sort key")
+	purgeCmd.Flags().StringVarP(&flagPurgePartitionKey, "partition-key", "p", "", "Optionally override the name of the partition key, resolved from the table key schema by default")
+	purgeCmd.Flags().StringVarP(&flagPurgeSortKey, "sort-key", "s", "", "Optionally override the name of the sort key, resolved from the table key schema by default")
+	purgeCmd.Flags().StringVarP(&flagPurgeCheckpoint, "checkpoint", "c", "", "Optional file to save progress to, so an interrupted purge can be resumed")
+	purgeCmd.Flags().BoolVar(&flagPurgeResume, "resume", false, "Resume the purge from the checkpoint file")
+	purgeCmd.Flags().Int32VarP(&flagPurgeSegments, "segments", "S", 1, "Number of segments to scan the table with in parallel")
+	purgeCmd.Flags().StringVarP(&flagPurgeFilterExp, "filter", "f", "", "Filter expression, only items matching the filter are purged")
+	purgeCmd.Flags().StringVarP(&flagPurgeFilterName, "attribute-name", "N", "", "Filter expression attribute names")
+	purgeCmd.Flags().StringVarP(&flagPurgeFilterValue, "attribute-value", "V", "", "Filter expression attribute values, :name=TYPE:value where the type is optional e.g. \":tenant=tenant-1,:age=N:30,:active=BOOL:true\"")
+	purgeCmd.Flags().StringVar(&flagPurgeFilterValuesJSON, "attribute-values-json", "", "Filter expression attribute values as a dynamodb json object e.g. '{\":age\": {\"N\": \"30\"}}'")
+	purgeCmd.Flags().StringVar(&flagPurgeWhere, "where", "", "Where condition compiled into a filter expression, names and values are escaped e.g. 'status = \"active\" and created > 2024-01-01'")
+	purgeCmd.Flags().StringVar(&flagPurgePartition, "partition", "", "Only purge the items of the partition with this partition key value, the partition is queried instead of scanning the table")
+	purgeCmd.Flags().StringVar(&flagPurgeSortCond, "sort-condition", "", "Condition on the sort key of the partition e.g. \"begins_with order#\" or \"between 1 and 10\"")
+	purgeCmd.Flags().StringVar(&flagPurgeMaxRCU, "max-rcu", "", "Limit the read capacity units consumed per second, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
+	purgeCmd.Flags().StringVar(&flagPurgeMaxWCU, "max-wcu", "", "Limit the write capacity units consumed per second, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
+	purgeCmd.Flags().IntVar(&flagPurgeMaxAttempts, "max-attempts", dynamodb.DefaultRetryPolicy.MaxAttempts, "Maximum number of requests made for a batch write, including the first request, before unprocessed items are an error")
+	purgeCmd.Flags().DurationVar(&flagPurgeRetryBase, "retry-base-delay", dynamodb.DefaultRetryPolicy.BaseDelay, "Delay before the first retry of unprocessed items, doubled for each retry with full jitter")
+	purgeCmd.Flags().DurationVar(&flagPurgeRetryMax, "retry-max-delay", dynamodb.DefaultRetryPolicy.MaxDelay, "Maximum delay between retries of unprocessed items")
 }
 
 // purgeFunc is the entry point for the purge command. It will purge a dynamodb table of all items
@@ -51,20 +82,69 @@ func purgeFunc(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if err = limitCapacity(ctx, dbClient, flagPurgeTableName, flagPurgeMaxRCU, flagPurgeMaxWCU); err != nil {
+		log.Error("could not limit capacity", "error", err)
+		os.Exit(1)
+	}
+
+	dbClient.SetRetryPolicy(dynamodb.RetryPolicy{
+		MaxAttempts: flagPurgeMaxAttempts,
+		BaseDelay:   flagPurgeRetryBase,
+		MaxDelay:    flagPurgeRetryMax,
+	})
+
 	msgEmitter := emitter.New()
 
 	goetyService := goety.New(dbClient, log, msgEmitter, flagRootDryRun)
 
+	var checkpoint goety.CheckpointStore
+	if flagPurgeCheckpoint != "" {
+		checkpoint = goety.NewFileCheckpoint(flagPurgeCheckpoint)
+	}
+
+	where, err := parseWhere(flagPurgeWhere)
+	if err != nil {
+		log.Error("error parsing where condition", "error", err)
+		os.Exit(1)
+	}
+
+	filterValues, err := parseFilterValues(flagPurgeFilterValue, flagPurgeFilterValuesJSON)
+	if err != nil {
+		log.Error("error parsing filter values", "error", err)
+		os.Exit(1)
+	}
+
+	var sortCondition *goety.SortCondition
+	if flagPurgeSortCond != "" {
+		sortCondition, err = goety.ParseSortCondition(flagPurgeSortCond)
+		if err != nil {
+			log.Error("could not parse sort condition", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	if !flagRootVerbose {
 		spin := spinner.New(msgEmitter)
 		spin.Start("starting purge")
 		defer spin.Stop("")
 	}
 
-	if err = goetyService.Purge(ctx, flagPurgeTableName, goety.TableKeys{
+	keys := goety.TableKeys{
 		PartitionKey: flagPurgePartitionKey,
 		SortKey:      flagPurgeSortKey,
-	}); err != nil {
+	}
+
+	if err = goetyService.Purge(ctx, flagPurgeTableName, keys,
+		goety.WithSegments(flagPurgeSegments),
+		goety.WithCheckpoint(checkpoint),
+		goety.WithResume(flagPurgeResume),
+		goety.WithFilterExpression(flagPurgeFilterExp),
+		goety.WithFilterNameAttrs(flagPurgeFilterName),
+		goety.WithFilterValues(filterValues),
+		goety.WithWhere(where),
+		goety.WithPartition(flagPurgePartition),
+		goety.WithSortCondition(sortCondition),
+	); err != nil {
 		log.Error("error purging table", "error", err)
 		os.Exit(1)
 	}
@@ -76,8 +156,20 @@ func parsePurgeFlag() error {
 	if flagPurgeTableName == "" {
 		return errors.New("table name is required")
 	}
-	if flagPurgePartitionKey == "" {
-		return errors.New("partition key is required")
+	if flagPurgeSegments < 1 {
+		return errors.New("segments must be at least 1")
+	}
+	if flagPurgeResume && flagPurgeCheckpoint == "" {
+		return errors.New("checkpoint is required to resume")
+	}
+	if flagPurgeSortCond != "" && flagPurgePartition == "" {
+		return errors.New("partition is required for a sort condition")
+	}
+	if flagPurgePartition != "" && flagPurgeSegments > 1 {
+		return errors.New("segments cannot be used with a partition, a partition is queried not scanned")
+	}
+	if err := validateRetry(flagPurgeMaxAttempts, flagPurgeRetryBase, flagPurgeRetryMax); err != nil {
+		return err
 	}
 	return nil
 }
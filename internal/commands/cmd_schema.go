@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/code-gorilla-au/goety/internal/dynamodb"
+	"github.com/code-gorilla-au/goety/internal/emitter"
+	"github.com/code-gorilla-au/goety/internal/goety"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/goety/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSchemaTableName  string
+	flagSchemaEndpoint   string
+	flagSchemaSegments   int32
+	flagSchemaSample     float64
+	flagSchemaEntityAttr string
+	flagSchemaFilePath   string
+	flagSchemaDir        string
+	flagSchemaMaxRCU     string
+)
+
+// schemaFileName - characters of an entity type that are replaced in schema file names
+var schemaFileName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [COMMAND]",
+	Short: "work with the schema of a dynamodb table",
+	Long:  "schema infers json schemas from the items of a dynamodb table",
+}
+
+var schemaInferCmd = &cobra.Command{
+	Use:   "infer -t [TABLE_NAME]",
+	Short: "infer a json schema per entity type of a dynamodb table",
+	Long:  "infer will scan all items within a dynamodb table, or a sample of the table, group the items by entity type and output a json schema per entity type. Items are grouped by the entity attribute, or by the prefix of their keys e.g. USER#123",
+	Run:   schemaInferFunc,
+}
+
+func init() {
+	schemaInferCmd.Flags().StringVarP(&flagSchemaTableName, "table", "t", "", "table name")
+	schemaInferCmd.Flags().StringVarP(&flagSchemaEndpoint, "endpoint", "e", "", "DynamoDB endpoint to connect to, if none is provide it will use the default aws endpoint")
+	schemaInferCmd.Flags().Int32VarP(&flagSchemaSegments, "segments", "S", 1, "Number of segments to scan the table with in parallel")
+	schemaInferCmd.Flags().Float64Var(&flagSchemaSample, "sample", 0, "Percentage of the table to scan e.g. 10. Scans the whole table by default")
+	schemaInferCmd.Flags().StringVar(&flagSchemaEntityAttr, "entity-attribute", "", "Attribute that holds the entity type of an item e.g. entityType, items without it are grouped by the prefix of their keys")
+	schemaInferCmd.Flags().StringVarP(&flagSchemaFilePath, "path", "p", "", "Optional file path to save the schemas to, as a json object keyed by entity type. Written to stdout by default")
+	schemaInferCmd.Flags().StringVar(&flagSchemaDir, "dir", "", "Optional directory to save a schema file per entity type to e.g. USER.schema.json")
+	schemaInferCmd.Flags().StringVar(&flagSchemaMaxRCU, "max-rcu", "", "Limit the read capacity units consumed per second, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
+
+	schemaCmd.AddCommand(schemaInferCmd)
+}
+
+// schemaInferFunc is the entry point for the schema infer command. It will infer a json schema per entity type of a dynamodb table
+func schemaInferFunc(cmd *cobra.Command, args []string) {
+	log := logging.New(flagRootVerbose)
+	ctx := context.Background()
+
+	if err := parseSchemaInferFlag(); err != nil {
+		log.Error("error parsing flags", "error", err)
+		os.Exit(1)
+	}
+
+	log.Debug("loading dynamodb client")
+	dbClient, err := dynamodb.NewClient(ctx, flagRootAwsRegion, flagSchemaEndpoint)
+	if err != nil {
+		log.Error("could not load client")
+		os.Exit(1)
+	}
+
+	if err = limitCapacity(ctx, dbClient, flagSchemaTableName, flagSchemaMaxRCU, ""); err != nil {
+		log.Error("could not limit capacity", "error", err)
+		os.Exit(1)
+	}
+
+	msgEmitter := emitter.New()
+
+	goetyService := goety.New(dbClient, log, msgEmitter, flagRootDryRun)
+
+	var spin *spinner.Spinner
+	if !flagRootVerbose {
+		spin = spinner.New(msgEmitter)
+		spin.Start("starting schema inference")
+	}
+
+	schemas, err := goetyService.InferSchema(ctx, flagSchemaTableName,
+		goety.WithSegments(flagSchemaSegments),
+		goety.WithSample(flagSchemaSample),
+		goety.WithEntityAttribute(flagSchemaEntityAttr),
+	)
+
+	if spin != nil {
+		spin.Stop("")
+	}
+
+	if err != nil {
+		log.Error("error inferring schema", "error", err)
+		os.Exit(1)
+	}
+
+	if err = writeSchemas(schemas); err != nil {
+		log.Error("error writing schemas", "error", err)
+		os.Exit(1)
+	}
+}
+
+// writeSchemas - writes a schema file per entity type to the directory, or the schemas keyed by entity type to the file path or stdout
+func writeSchemas(schemas goety.Schemas) error {
+	if flagSchemaDir == "" {
+		data, err := json.MarshalIndent(schemas, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+
+		if flagSchemaFilePath == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+
+		return os.WriteFile(flagSchemaFilePath, data, 0644)
+	}
+
+	names, err := schemaFileNames(schemas)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(flagSchemaDir, 0755); err != nil {
+		return err
+	}
+
+	for entity, schema := range schemas {
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+
+		if err = os.WriteFile(filepath.Join(flagSchemaDir, names[entity]), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// schemaFileNames - returns the schema file name of each entity type, entity types that sanitise to the same file name are an error.
+// Names are compared ignoring case, so schemas do not overwrite each other on case insensitive file systems.
+func schemaFileNames(schemas goety.Schemas) (map[string]string, error) {
+	names := map[string]string{}
+	entities := map[string]string{}
+
+	for _, entity := range slices.Sorted(maps.Keys(schemas)) {
+		name := schemaFileName.ReplaceAllString(entity, "_") + ".schema.json"
+		if other, ok := entities[strings.ToLower(name)]; ok {
+			return nil, fmt.Errorf("entity types %q and %q would both be written to %s, use --path to write the schemas to a single file", other, entity, name)
+		}
+
+		entities[strings.ToLower(name)] = entity
+		names[entity] = name
+	}
+
+	return names, nil
+}
+
+// parseSchemaInferFlag will validate the flags passed to the schema infer command
+func parseSchemaInferFlag() error {
+	if flagSchemaTableName == "" {
+		return errors.New("table name is required")
+	}
+	if flagSchemaSegments < 1 {
+		return errors.New("segments must be at least 1")
+	}
+	if flagSchemaSample < 0 || flagSchemaSample > 100 {
+		return errors.New("sample must be a percentage between 0 and 100")
+	}
+	if flagSchemaFilePath != "" && flagSchemaDir != "" {
+		return errors.New("path and dir cannot be used together")
+	}
+	return nil
+}
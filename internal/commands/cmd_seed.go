@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"os"
+	"time"
 
 	"github.com/code-gorilla-au/goety/internal/dynamodb"
 	"github.com/code-gorilla-au/goety/internal/emitter"
@@ -14,15 +15,25 @@ import (
 )
 
 var (
-	flagSeedTableName string
-	flagSeedEndpoint  string
-	flagSeedFile      string
+	flagSeedTableName   string
+	flagSeedEndpoint    string
+	flagSeedFile        string
+	flagSeedRawInput    bool
+	flagSeedFormat      string
+	flagSeedTypes       []string
+	flagSeedTransform   string
+	flagSeedMaxWCU      string
+	flagSeedContinue    bool
+	flagSeedDeadFile    string
+	flagSeedMaxAttempts int
+	flagSeedRetryBase   time.Duration
+	flagSeedRetryMax    time.Duration
 )
 
 var seedCmd = &cobra.Command{
 	Use:   "seed -t [TABLE_NAME] -f [FILE_PATH]",
 	Short: "seed a dynamodb table from file",
-	Long:  "seed will read a json file and write the contents to a dynamodb table",
+	Long:  "seed will read a json, json lines or csv file and write the contents to a dynamodb table",
 	Run:   seedFunc,
 }
 
@@ -30,6 +41,16 @@ func init() {
 	seedCmd.Flags().StringVarP(&flagSeedTableName, "table", "t", "", "Table name")
 	seedCmd.Flags().StringVarP(&flagSeedEndpoint, "endpoint", "e", "", "DynamoDB endpoint to connect to, if none is provide it will use the default aws endpoint")
 	seedCmd.Flags().StringVarP(&flagSeedFile, "file", "f", "", "File path")
+	seedCmd.Flags().BoolVarP(&flagSeedRawInput, "raw-input", "R", false, "Optional flag to treat the file as dynamodb json, as written by dump --raw-output. Detected automatically when not set, --raw-input=false treats the file as plain json")
+	seedCmd.Flags().StringVar(&flagSeedFormat, "format", "", "Input format: json, jsonl or csv. Defaults to the file extension, or is detected from the file")
+	seedCmd.Flags().StringSliceVar(&flagSeedTypes, "column-types", []string{}, "Optional csv column types e.g. price:N,tags:SS, overriding type hints in the csv header")
+	seedCmd.Flags().StringVar(&flagSeedTransform, "transform", "", "Optional json file of transform rules applied to each item before it is seeded")
+	seedCmd.Flags().BoolVar(&flagSeedContinue, "continue-on-error", false, "Continue seeding when an item fails, failed items and their errors are written to the dead letter file")
+	seedCmd.Flags().StringVar(&flagSeedDeadFile, "dead-letter", "dead-letter.json", "File the failed items are written to when continuing on error, only created if an item fails")
+	seedCmd.Flags().StringVar(&flagSeedMaxWCU, "max-wcu", "", "Limit the write capacity units consumed per second, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
+	seedCmd.Flags().IntVar(&flagSeedMaxAttempts, "max-attempts", dynamodb.DefaultRetryPolicy.MaxAttempts, "Maximum number of requests made for a batch write, including the first request, before unprocessed items are an error")
+	seedCmd.Flags().DurationVar(&flagSeedRetryBase, "retry-base-delay", dynamodb.DefaultRetryPolicy.BaseDelay, "Delay before the first retry of unprocessed items, doubled for each retry with full jitter")
+	seedCmd.Flags().DurationVar(&flagSeedRetryMax, "retry-max-delay", dynamodb.DefaultRetryPolicy.MaxDelay, "Maximum delay between retries of unprocessed items")
 }
 
 // purgeFunc is the entry point for the purge command. It will purge a dynamodb table of all items
@@ -49,6 +70,17 @@ func seedFunc(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if err = limitCapacity(ctx, dbClient, flagSeedTableName, "", flagSeedMaxWCU); err != nil {
+		log.Error("could not limit capacity", "error", err)
+		os.Exit(1)
+	}
+
+	dbClient.SetRetryPolicy(dynamodb.RetryPolicy{
+		MaxAttempts: flagSeedMaxAttempts,
+		BaseDelay:   flagSeedRetryBase,
+		MaxDelay:    flagSeedRetryMax,
+	})
+
 	msgEmitter := emitter.New()
 
 	goetyService := goety.New(dbClient, log, msgEmitter, flagRootDryRun)
@@ -66,7 +98,46 @@ func seedFunc(cmd *cobra.Command, args []string) {
 	}
 	defer file.Close()
 
-	if err = goetyService.Seed(ctx, flagSeedTableName, file); err != nil {
+	columnTypes, err := goety.ParseColumnTypes(flagSeedTypes)
+	if err != nil {
+		log.Error("error parsing flags", "error", err)
+		os.Exit(1)
+	}
+
+	transform, err := loadTransform(flagSeedTransform)
+	if err != nil {
+		log.Error("error loading transform rules", "error", err)
+		os.Exit(1)
+	}
+
+	deadLetter := goety.NewFileDeadLetter(flagSeedDeadFile)
+
+	seedOpts := []goety.SeedFuncOpts{
+		goety.WithInputFormat(seedFormat()),
+		goety.WithColumnTypes(columnTypes),
+		goety.WithSeedTransform(transform),
+		goety.WithContinueOnError(flagSeedContinue),
+		goety.WithDeadLetter(deadLetter),
+	}
+
+	// raw input is detected from the file unless the flag is set, --raw-input=false seeds plain json that looks like dynamodb json
+	if cmd.Flags().Changed("raw-input") {
+		seedOpts = append(seedOpts, goety.WithRawInput(flagSeedRawInput))
+	}
+
+	err = goetyService.Seed(ctx, flagSeedTableName, file, seedOpts...)
+
+	if closeErr := deadLetter.Close(); closeErr != nil {
+		log.Error("error writing dead letter file", "error", closeErr)
+		os.Exit(1)
+	}
+
+	var seedErr *goety.SeedError
+	if errors.As(err, &seedErr) {
+		log.Error("seed complete with failed items", "inserted", seedErr.Succeeded, "failed", seedErr.Failed, "deadLetter", flagSeedDeadFile)
+		os.Exit(1)
+	}
+	if err != nil {
 		log.Error("error seeding table", "error", err)
 		os.Exit(1)
 	}
@@ -78,5 +149,22 @@ func parseSeedFlag() error {
 	if flagSeedTableName == "" {
 		return errors.New("table name is required")
 	}
+	if format := seedFormat(); format != "" {
+		if _, err := goety.ParseSeedFormat(string(format)); err != nil {
+			return err
+		}
+	}
+	if err := validateRetry(flagSeedMaxAttempts, flagSeedRetryBase, flagSeedRetryMax); err != nil {
+		return err
+	}
 	return nil
 }
+
+// seedFormat - returns the format flag, or the format implied by the file extension
+func seedFormat() goety.Format {
+	if flagSeedFormat != "" {
+		return goety.Format(flagSeedFormat)
+	}
+
+	return goety.FormatFromPath(flagSeedFile)
+}
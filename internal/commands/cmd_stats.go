@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/code-gorilla-au/goety/internal/dynamodb"
+	"github.com/code-gorilla-au/goety/internal/emitter"
+	"github.com/code-gorilla-au/goety/internal/goety"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/goety/internal/spinner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStatsTableName        string
+	flagStatsEndpoint         string
+	flagStatsSegments         int32
+	flagStatsSample           float64
+	flagStatsTop              int
+	flagStatsJSON             bool
+	flagStatsFilterExp        string
+	flagStatsFilterName       string
+	flagStatsFilterValue      string
+	flagStatsFilterValuesJSON string
+	flagStatsWhere            string
+	flagStatsMaxRCU           string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats -t [TABLE_NAME]",
+	Short: "profile the items of a dynamodb table",
+	Long:  "stats will scan all items within a dynamodb table, or a sample of the table, and report the item count, item sizes, attribute names and types and, the hottest partitions",
+	Run:   statsFunc,
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&flagStatsTableName, "table", "t", "", "table name")
+	statsCmd.Flags().StringVarP(&flagStatsEndpoint, "endpoint", "e", "", "DynamoDB endpoint to connect to, if none is provide it will use the default aws endpoint")
+	statsCmd.Flags().Int32VarP(&flagStatsSegments, "segments", "S", 1, "Number of segments to scan the table with in parallel")
+	statsCmd.Flags().Float64Var(&flagStatsSample, "sample", 0, "Percentage of the table to scan e.g. 10, the item count is estimated from the sample. Scans the whole table by default")
+	statsCmd.Flags().IntVar(&flagStatsTop, "top", 10, "Number of hottest partitions to report")
+	statsCmd.Flags().BoolVar(&flagStatsJSON, "json", false, "Output the stats as json")
+	statsCmd.Flags().StringVarP(&flagStatsFilterExp, "filter", "f", "", "Filter expression, only items matching the filter are profiled")
+	statsCmd.Flags().StringVarP(&flagStatsFilterName, "attribute-name", "N", "", "Filter expression attribute names")
+	statsCmd.Flags().StringVarP(&flagStatsFilterValue, "attribute-value", "V", "", "Filter expression attribute values, :name=TYPE:value where the type is optional e.g. \":tenant=tenant-1,:age=N:30,:active=BOOL:true\"")
+	statsCmd.Flags().StringVar(&flagStatsFilterValuesJSON, "attribute-values-json", "", "Filter expression attribute values as a dynamodb json object e.g. '{\":age\": {\"N\": \"30\"}}'")
+	statsCmd.Flags().StringVar(&flagStatsWhere, "where", "", "Where condition compiled into a filter expression, names and values are escaped e.g. 'status = \"active\" and created > 2024-01-01'")
+	statsCmd.Flags().StringVar(&flagStatsMaxRCU, "max-rcu", "", "Limit the read capacity units consumed per second, as units e.g. 100 or a percentage of the provisioned table capacity e.g. 50%")
+}
+
+// statsFunc is the entry point for the stats command. It will profile the items of a dynamodb table
+func statsFunc(cmd *cobra.Command, args []string) {
+	log := logging.New(flagRootVerbose)
+	ctx := context.Background()
+
+	if err := parseStatsFlag(); err != nil {
+		log.Error("error parsing flags", "error", err)
+		os.Exit(1)
+	}
+
+	log.Debug("loading dynamodb client")
+	dbClient, err := dynamodb.NewClient(ctx, flagRootAwsRegion, flagStatsEndpoint)
+	if err != nil {
+		log.Error("could not load client")
+		os.Exit(1)
+	}
+
+	if err = limitCapacity(ctx, dbClient, flagStatsTableName, flagStatsMaxRCU, ""); err != nil {
+		log.Error("could not limit capacity", "error", err)
+		os.Exit(1)
+	}
+
+	where, err := parseWhere(flagStatsWhere)
+	if err != nil {
+		log.Error("error parsing where condition", "error", err)
+		os.Exit(1)
+	}
+
+	filterValues, err := parseFilterValues(flagStatsFilterValue, flagStatsFilterValuesJSON)
+	if err != nil {
+		log.Error("error parsing filter values", "error", err)
+		os.Exit(1)
+	}
+
+	msgEmitter := emitter.New()
+
+	goetyService := goety.New(dbClient, log, msgEmitter, flagRootDryRun)
+
+	var spin *spinner.Spinner
+	if !flagRootVerbose {
+		spin = spinner.New(msgEmitter)
+		spin.Start("starting profile")
+	}
+
+	stats, err := goetyService.Stats(ctx, flagStatsTableName,
+		goety.WithSegments(flagStatsSegments),
+		goety.WithSample(flagStatsSample),
+		goety.WithTopPartitions(flagStatsTop),
+		goety.WithFilterExpression(flagStatsFilterExp),
+		goety.WithFilterNameAttrs(flagStatsFilterName),
+		goety.WithFilterValues(filterValues),
+		goety.WithWhere(where),
+	)
+
+	if spin != nil {
+		spin.Stop("")
+	}
+
+	if err != nil {
+		log.Error("error profiling table", "error", err)
+		os.Exit(1)
+	}
+
+	if flagStatsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(stats)
+	} else {
+		err = stats.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Error("error writing stats", "error", err)
+		os.Exit(1)
+	}
+}
+
+// parseStatsFlag will validate the flags passed to the stats command
+func parseStatsFlag() error {
+	if flagStatsTableName == "" {
+		return errors.New("table name is required")
+	}
+	if flagStatsSegments < 1 {
+		return errors.New("segments must be at least 1")
+	}
+	if flagStatsSample < 0 || flagStatsSample > 100 {
+		return errors.New("sample must be a percentage between 0 and 100")
+	}
+	if flagStatsTop < 1 {
+		return errors.New("top must be at least 1")
+	}
+	return nil
+}
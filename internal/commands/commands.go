@@ -1,6 +1,16 @@
 package commands
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"errors"
+	"maps"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/goety/internal/dynamodb"
+	"github.com/code-gorilla-au/goety/internal/goety"
+	"github.com/spf13/cobra"
+)
 
 var (
 	flagRootVerbose   = false
@@ -22,9 +32,79 @@ func init() {
 	rootCmd.AddCommand(purgeCmd)
 	rootCmd.AddCommand(dumpCmd)
 	rootCmd.AddCommand(seedCmd)
+	rootCmd.AddCommand(copyCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(schemaCmd)
 }
 
 func Execute() error {
 
 	return rootCmd.Execute()
 }
+
+// loadTransform - loads the transform rules file, returns nil when no file is provided
+func loadTransform(path string) (*goety.Transform, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	return goety.LoadTransform(path)
+}
+
+// parseFilterValues - parses the typed filter values and, the dynamodb json filter values which take precedence
+func parseFilterValues(values string, valuesJSON string) (map[string]types.AttributeValue, error) {
+	parsed, err := goety.ParseFilterValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedJSON, err := goety.ParseFilterValuesJSON(valuesJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	maps.Copy(parsed, parsedJSON)
+	return parsed, nil
+}
+
+// parseWhere - parses the where condition, returns nil when no condition is provided
+func parseWhere(where string) (*goety.Where, error) {
+	if where == "" {
+		return nil, nil
+	}
+
+	return goety.ParseWhere(where)
+}
+
+// limitCapacity - limits the read and write capacity the client consumes on the table, empty limits do not limit capacity
+func limitCapacity(ctx context.Context, client *dynamodb.Client, tableName string, maxRCU string, maxWCU string) error {
+	read, err := dynamodb.ParseCapacityLimit(maxRCU)
+	if err != nil {
+		return err
+	}
+
+	write, err := dynamodb.ParseCapacityLimit(maxWCU)
+	if err != nil {
+		return err
+	}
+
+	if !read.IsSet() && !write.IsSet() {
+		return nil
+	}
+
+	return client.LimitCapacity(ctx, tableName, read, write)
+}
+
+// validateRetry - validates the retry flags of batch operations
+func validateRetry(maxAttempts int, baseDelay time.Duration, maxDelay time.Duration) error {
+	if maxAttempts < 1 {
+		return errors.New("max attempts must be at least 1")
+	}
+	if baseDelay <= 0 || maxDelay <= 0 {
+		return errors.New("retry delays must be greater than zero")
+	}
+	if maxDelay < baseDelay {
+		return errors.New("retry max delay must be at least the retry base delay")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package dynamodb
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -11,13 +12,24 @@ import (
 
 // NewClient - creates a new opinionated dynamodb client
 func NewClient(ctx context.Context, region string, endpoint string) (*Client, error) {
+	return NewProfileClient(ctx, region, endpoint, "")
+}
+
+// NewProfileClient - creates a new opinionated dynamodb client, loading credentials from the named shared config profile.
+// When the profile is empty, the default credential chain is used.
+func NewProfileClient(ctx context.Context, region string, endpoint string, profile string) (*Client, error) {
 	ops := func(o *ddb.Options) {
 		o.Region = region
 		if endpoint != "" {
 			o.BaseEndpoint = &endpoint
 		}
 	}
-	return NewWith(ctx, func(lo *config.LoadOptions) error { return nil }, ops)
+	return NewWith(ctx, func(lo *config.LoadOptions) error {
+		if profile != "" {
+			lo.SharedConfigProfile = profile
+		}
+		return nil
+	}, ops)
 }
 
 // NewWith - creates a new dynamodb client with exposed functional options.
@@ -41,18 +53,64 @@ func NewWith(ctx context.Context, configOpts func(*config.LoadOptions) error, db
 
 // Scan - scans a dynamodb table
 func (c *Client) Scan(ctx context.Context, input *ddb.ScanInput) (*ddb.ScanOutput, error) {
+	if err := c.limit(ctx, c.readLimiter, &input.ReturnConsumedCapacity); err != nil {
+		return nil, err
+	}
+
 	output, err := c.db.Scan(ctx, input)
 	if err != nil {
 		c.logger.Error("could not scan table", "error", err)
 		return output, err
 	}
 
+	// without reported capacity, assume an eventually consistent read of half a unit per item scanned
+	c.readLimiter.consume(consumedUnits(singleConsumed(output.ConsumedCapacity), float64(output.ScannedCount)/2))
+
+	return output, nil
+}
+
+// Query - queries a dynamodb table or index
+func (c *Client) Query(ctx context.Context, input *ddb.QueryInput) (*ddb.QueryOutput, error) {
+	if err := c.limit(ctx, c.readLimiter, &input.ReturnConsumedCapacity); err != nil {
+		return nil, err
+	}
+
+	output, err := c.db.Query(ctx, input)
+	if err != nil {
+		c.logger.Error("could not query table", "error", err)
+		return output, err
+	}
+
+	c.readLimiter.consume(consumedUnits(singleConsumed(output.ConsumedCapacity), float64(output.ScannedCount)/2))
+
+	return output, nil
+}
+
+// DescribeTable - describes a dynamodb table, including its key schema
+func (c *Client) DescribeTable(ctx context.Context, input *ddb.DescribeTableInput) (*ddb.DescribeTableOutput, error) {
+	output, err := c.db.DescribeTable(ctx, input)
+	if err != nil {
+		c.logger.Error("could not describe table", "error", err)
+		return output, err
+	}
+
 	return output, nil
 }
 
 // Put - puts an item into a dynamodb table
 func (c *Client) Put(ctx context.Context, input *ddb.PutItemInput) (*ddb.PutItemOutput, error) {
-	return c.db.PutItem(ctx, input)
+	if err := c.limit(ctx, c.writeLimiter, &input.ReturnConsumedCapacity); err != nil {
+		return nil, err
+	}
+
+	output, err := c.db.PutItem(ctx, input)
+	if err != nil {
+		return output, err
+	}
+
+	c.writeLimiter.consume(consumedUnits(singleConsumed(output.ConsumedCapacity), 1))
+
+	return output, nil
 }
 
 // BatchDeleteItems - deletes items in a batch Note, max size is 25 items within a batch
@@ -79,33 +137,104 @@ func (c *Client) BatchDeleteItems(ctx context.Context, tableName string, keys []
 		return &ddb.BatchWriteItemOutput{}, nil
 	}
 
-	output, err := c.db.BatchWriteItem(ctx, &input)
-	if err != nil {
-		c.logger.Error("could not batch delete items", "error", err)
-		return output, err
+	return c.batchWrite(ctx, &input)
+}
+
+// BatchPutItems - puts items in a batch Note, max size is 25 items within a batch
+func (c *Client) BatchPutItems(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*ddb.BatchWriteItemOutput, error) {
+	txnWrite := []types.WriteRequest{}
+
+	for _, item := range items {
+		c.logger.Debug("adding item to batch put", "item", JSONStringify(item))
+		txnWrite = append(txnWrite, types.WriteRequest{
+			PutRequest: &types.PutRequest{
+				Item: item,
+			},
+		})
 	}
 
-	if output.UnprocessedItems == nil {
-		c.logger.Debug("batch delete complete")
-		return output, nil
+	input := ddb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{
+			tableName: txnWrite,
+		},
 	}
 
-	c.logger.Debug("unprocessed items detected, processing")
+	if c.dryRun {
+		c.logger.Debug("dry run enabled, skipping batch put", "items", JSONStringify(input))
+		return &ddb.BatchWriteItemOutput{}, nil
+	}
+
+	return c.batchWrite(ctx, &input)
+}
+
+// batchWrite - writes the batch, retrying any unprocessed items with backoff until all items have been processed.
+// Returns an UnprocessedItemsError when items are still unprocessed once the retry policy is exhausted.
+func (c *Client) batchWrite(ctx context.Context, input *ddb.BatchWriteItemInput) (*ddb.BatchWriteItemOutput, error) {
+	policy := c.retryPolicy.withDefaults()
+
+	output, err := c.batchWriteItem(ctx, input)
+	if err != nil {
+		c.logger.Error("could not batch write items", "error", err)
+		return output, err
+	}
 
 	unprocessedItems := output.UnprocessedItems
 
-	for len(unprocessedItems) > 0 {
-		unprocessedInput := ddb.BatchWriteItemInput{
-			RequestItems: unprocessedItems,
+	for attempt := 1; len(unprocessedItems) > 0; attempt++ {
+		if attempt >= policy.MaxAttempts {
+			return output, &UnprocessedItemsError{
+				Attempts: attempt,
+				Items:    unprocessedItems,
+			}
+		}
+
+		delay := policy.delay(attempt - 1)
+		c.logger.Debug("unprocessed items detected, retrying", "attempt", attempt, "delay", delay)
+		if err = c.sleepContext(ctx, delay); err != nil {
+			return output, err
 		}
 
-		unprocessedOutput, err := c.db.BatchWriteItem(ctx, &unprocessedInput)
+		unprocessedOutput, err := c.batchWriteItem(ctx, &ddb.BatchWriteItemInput{
+			RequestItems: unprocessedItems,
+		})
 		if err != nil {
-			c.logger.Error("could not batch delete items", "error", err)
+			c.logger.Error("could not batch write items", "error", err)
 			return unprocessedOutput, err
 		}
 
 		unprocessedItems = unprocessedOutput.UnprocessedItems
 	}
-	return output, err
+
+	c.logger.Debug("batch write complete")
+	return output, nil
+}
+
+// sleepContext - sleeps between retries, returning early if the context is done
+func (c *Client) sleepContext(ctx context.Context, d time.Duration) error {
+	if c.sleep != nil {
+		return c.sleep(ctx, d)
+	}
+
+	return sleepContext(ctx, d)
+}
+
+// batchWriteItem - writes a single batch request, paced by the write capacity limit
+func (c *Client) batchWriteItem(ctx context.Context, input *ddb.BatchWriteItemInput) (*ddb.BatchWriteItemOutput, error) {
+	if err := c.limit(ctx, c.writeLimiter, &input.ReturnConsumedCapacity); err != nil {
+		return nil, err
+	}
+
+	output, err := c.db.BatchWriteItem(ctx, input)
+	if err != nil {
+		return output, err
+	}
+
+	// without reported capacity, assume a unit per write request
+	requests := 0
+	for _, writes := range input.RequestItems {
+		requests += len(writes)
+	}
+	c.writeLimiter.consume(consumedUnits(output.ConsumedCapacity, float64(requests)))
+
+	return output, nil
 }
@@ -51,6 +51,98 @@ func TestClient_Scan(t *testing.T) {
 	odize.AssertNoError(t, err)
 }
 
+func TestClient_Query(t *testing.T) {
+	logger := logging.New(false)
+	ctx := logging.WithContext(context.Background(), logger)
+	var client Client
+	var db ddbClientMock
+
+	group := odize.NewGroup(t, nil)
+	group.BeforeEach(func() {
+		db = ddbClientMock{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"key": &types.AttributeValueMemberS{Value: "value"},
+						},
+					},
+				}, nil
+			},
+		}
+
+		client = Client{
+			logger: logger,
+			db:     &db,
+		}
+	})
+
+	err := group.
+		Test("should query table", func(t *testing.T) {
+			input := dynamodb.QueryInput{}
+			result, err := client.Query(ctx, &input)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, result.Items[0]["key"].(*types.AttributeValueMemberS).Value, "value")
+		}).
+		Test("should return error on db error", func(t *testing.T) {
+			db.QueryFunc = func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return nil, errors.ErrUnsupported
+			}
+
+			_, err := client.Query(ctx, &dynamodb.QueryInput{})
+			odize.AssertError(t, err)
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func TestClient_DescribeTable(t *testing.T) {
+	logger := logging.New(false)
+	ctx := logging.WithContext(context.Background(), logger)
+	var client Client
+	var db ddbClientMock
+
+	group := odize.NewGroup(t, nil)
+	group.BeforeEach(func() {
+		db = ddbClientMock{
+			DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+				return &dynamodb.DescribeTableOutput{
+					Table: &types.TableDescription{
+						TableName: params.TableName,
+					},
+				}, nil
+			},
+		}
+
+		client = Client{
+			logger: logger,
+			db:     &db,
+		}
+	})
+
+	err := group.
+		Test("should describe table", func(t *testing.T) {
+			table := "table"
+			result, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &table})
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, "table", *result.Table.TableName)
+		}).
+		Test("should return error on db error", func(t *testing.T) {
+			db.DescribeTableFunc = func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+				return nil, errors.ErrUnsupported
+			}
+
+			_, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{})
+			odize.AssertError(t, err)
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
 func TestClient_BatchDeleteItems(t *testing.T) {
 	logger := logging.New(true)
 	ctx := logging.WithContext(context.Background(), logger)
@@ -157,3 +249,89 @@ func TestClient_BatchDeleteItems(t *testing.T) {
 
 	odize.AssertNoError(t, err)
 }
+
+func TestClient_BatchPutItems(t *testing.T) {
+	logger := logging.New(true)
+	ctx := logging.WithContext(context.Background(), logger)
+	var client Client
+	var db ddbClientMock
+
+	batchWrite := 0
+
+	group := odize.NewGroup(t, nil)
+	group.BeforeEach(func() {
+		db = ddbClientMock{
+			BatchWriteItemFunc: func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+				batchWrite++
+
+				if batchWrite == 1 {
+					return &dynamodb.BatchWriteItemOutput{
+						UnprocessedItems: map[string][]types.WriteRequest{
+							"table": {
+								{
+									PutRequest: &types.PutRequest{
+										Item: map[string]types.AttributeValue{
+											"key": &types.AttributeValueMemberS{Value: "value"},
+										},
+									},
+								},
+							},
+						},
+					}, nil
+				}
+
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			},
+		}
+
+		client = Client{
+			logger: logger,
+			db:     &db,
+		}
+	})
+
+	group.AfterEach(func() {
+		batchWrite = 0
+	})
+
+	input := []map[string]types.AttributeValue{
+		{
+			"key": &types.AttributeValueMemberS{Value: "value"},
+		},
+	}
+
+	err := group.
+		Test("should not make db call on dry run", func(t *testing.T) {
+			client.dryRun = true
+
+			_, err := client.BatchPutItems(ctx, "table", input)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 0, batchWrite)
+		}).
+		Test("should send put requests", func(t *testing.T) {
+			_, err := client.BatchPutItems(ctx, "table", input)
+			odize.AssertNoError(t, err)
+
+			request := db.BatchWriteItemCalls()[0].Params.RequestItems["table"][0]
+			odize.AssertTrue(t, request.PutRequest != nil)
+			odize.AssertTrue(t, request.DeleteRequest == nil)
+		}).
+		Test("should run twice for unprocessed", func(t *testing.T) {
+			_, err := client.BatchPutItems(ctx, "table", input)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 2, batchWrite)
+		}).
+		Test("should return error on db error", func(t *testing.T) {
+			db.BatchWriteItemFunc = func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{}, errors.ErrUnsupported
+			}
+
+			_, err := client.BatchPutItems(ctx, "table", input)
+			odize.AssertError(t, err)
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
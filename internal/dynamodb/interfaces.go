@@ -10,9 +10,15 @@ type Scanner interface {
 	Scan(ctx context.Context, input *ddb.ScanInput) (*ddb.ScanOutput, error)
 }
 
+type Querier interface {
+	Query(ctx context.Context, input *ddb.QueryInput) (*ddb.QueryOutput, error)
+}
+
 //go:generate moq -rm -stub -out mocks_test.go . ddbClient
 type ddbClient interface {
 	Scan(ctx context.Context, params *ddb.ScanInput, optFns ...func(*ddb.Options)) (*ddb.ScanOutput, error)
+	Query(ctx context.Context, params *ddb.QueryInput, optFns ...func(*ddb.Options)) (*ddb.QueryOutput, error)
 	BatchWriteItem(ctx context.Context, params *ddb.BatchWriteItemInput, optFns ...func(*ddb.Options)) (*ddb.BatchWriteItemOutput, error)
 	PutItem(ctx context.Context, params *ddb.PutItemInput, optFns ...func(*ddb.Options)) (*ddb.PutItemOutput, error)
+	DescribeTable(ctx context.Context, params *ddb.DescribeTableInput, optFns ...func(*ddb.Options)) (*ddb.DescribeTableOutput, error)
 }
@@ -3,6 +3,7 @@ package dynamodb
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
@@ -10,6 +11,7 @@ import (
 // ScanIterator - Creates an iterator function for a DynamoDB scan function.
 // The iterator function will return the next page of results on each call, until there are no more results.
 // If the iterator is done, the output will be nil and, the last return value will be true.
+// An ExclusiveStartKey set on the first input is kept, to resume from a previous scan.
 //
 // Example:
 //
@@ -22,6 +24,7 @@ import (
 //	output, err, done := next(input)
 func ScanIterator(ctx context.Context, scanner Scanner) func(input *ddb.ScanInput) (*ddb.ScanOutput, error, bool) {
 	done := false
+	started := false
 	var lastEvaluatedKey map[string]types.AttributeValue
 
 	return func(input *ddb.ScanInput) (*ddb.ScanOutput, error, bool) {
@@ -29,7 +32,10 @@ func ScanIterator(ctx context.Context, scanner Scanner) func(input *ddb.ScanInpu
 			return nil, nil, done
 		}
 
-		input.ExclusiveStartKey = lastEvaluatedKey
+		if started {
+			input.ExclusiveStartKey = lastEvaluatedKey
+		}
+		started = true
 
 		output, err := scanner.Scan(ctx, input)
 		if err != nil {
@@ -46,3 +52,72 @@ func ScanIterator(ctx context.Context, scanner Scanner) func(input *ddb.ScanInpu
 		return output, nil, done
 	}
 }
+
+// SegmentScanIterator - Creates an iterator function for a single segment of a DynamoDB parallel scan.
+// The iterator behaves the same as ScanIterator, with the segment and total segments set on each input.
+//
+// Example:
+//
+//	next := dynamodb.SegmentScanIterator(ctx, scanner, 0, 4)
+//
+//	input := &ddb.ScanInput{
+//	    TableName: aws.String("my-table"),
+//	}
+//
+//	output, err, done := next(input)
+func SegmentScanIterator(ctx context.Context, scanner Scanner, segment int32, totalSegments int32) func(input *ddb.ScanInput) (*ddb.ScanOutput, error, bool) {
+	next := ScanIterator(ctx, scanner)
+
+	return func(input *ddb.ScanInput) (*ddb.ScanOutput, error, bool) {
+		input.Segment = aws.Int32(segment)
+		input.TotalSegments = aws.Int32(totalSegments)
+
+		return next(input)
+	}
+}
+
+// QueryIterator - Creates an iterator function for a DynamoDB query function.
+// The iterator function will return the next page of results on each call, until there are no more results.
+// If the iterator is done, the output will be nil and, the last return value will be true.
+// An ExclusiveStartKey set on the first input is kept, to resume from a previous query.
+//
+// Example:
+//
+//	next := dynamodb.QueryIterator(ctx, querier)
+//
+//	input := &ddb.QueryInput{
+//	    TableName:              aws.String("my-table"),
+//	    KeyConditionExpression: aws.String("pk = :pk"),
+//	}
+//
+//	output, err, done := next(input)
+func QueryIterator(ctx context.Context, querier Querier) func(input *ddb.QueryInput) (*ddb.QueryOutput, error, bool) {
+	done := false
+	started := false
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	return func(input *ddb.QueryInput) (*ddb.QueryOutput, error, bool) {
+		if done {
+			return nil, nil, done
+		}
+
+		if started {
+			input.ExclusiveStartKey = lastEvaluatedKey
+		}
+		started = true
+
+		output, err := querier.Query(ctx, input)
+		if err != nil {
+			done = true
+			return output, err, done
+		}
+
+		lastEvaluatedKey = output.LastEvaluatedKey
+
+		if lastEvaluatedKey == nil {
+			done = true
+		}
+
+		return output, nil, done
+	}
+}
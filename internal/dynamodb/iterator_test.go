@@ -84,3 +84,127 @@ func TestScanIterator(t *testing.T) {
 		Run()
 	odize.AssertNoError(t, err)
 }
+
+func TestSegmentScanIterator(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var mockScanner *mockDDBScanner
+	var inputs []*dynamodb.ScanInput
+
+	group.BeforeEach(func() {
+		inputs = []*dynamodb.ScanInput{}
+		mockScanner = &mockDDBScanner{
+			ScanFunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				inputs = append(inputs, input)
+				return &dynamodb.ScanOutput{}, nil
+			},
+		}
+	})
+
+	err := group.
+		Test("should set segment on input", func(t *testing.T) {
+			next := SegmentScanIterator(context.Background(), mockScanner, 2, 4)
+
+			_, err, _ := next(&dynamodb.ScanInput{})
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, int32(2), *inputs[0].Segment)
+			odize.AssertEqual(t, int32(4), *inputs[0].TotalSegments)
+		}).
+		Test("should return done when segment has no more pages", func(t *testing.T) {
+			next := SegmentScanIterator(context.Background(), mockScanner, 0, 2)
+
+			_, err, done := next(&dynamodb.ScanInput{})
+			odize.AssertNoError(t, err)
+			odize.AssertTrue(t, done)
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+type mockDDBQuerier struct {
+	QueryFunc func(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+}
+
+func (m *mockDDBQuerier) Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	return m.QueryFunc(ctx, input)
+}
+
+func TestQueryIterator(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var mockQuerier *mockDDBQuerier
+	var inputs []*dynamodb.QueryInput
+
+	lastKey := map[string]types.AttributeValue{
+		"key": &types.AttributeValueMemberS{Value: "value"},
+	}
+
+	group.BeforeEach(func() {
+		inputs = []*dynamodb.QueryInput{}
+		mockQuerier = &mockDDBQuerier{
+			QueryFunc: func(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+				inputs = append(inputs, &dynamodb.QueryInput{ExclusiveStartKey: input.ExclusiveStartKey})
+				if len(inputs) == 1 {
+					return &dynamodb.QueryOutput{LastEvaluatedKey: lastKey}, nil
+				}
+				return &dynamodb.QueryOutput{}, nil
+			},
+		}
+	})
+
+	err := group.
+		Test("iterator should continue from the last evaluated key", func(t *testing.T) {
+			next := QueryIterator(context.Background(), mockQuerier)
+
+			_, err, done := next(&dynamodb.QueryInput{})
+			odize.AssertNoError(t, err)
+			odize.AssertFalse(t, done)
+
+			_, err, done = next(&dynamodb.QueryInput{})
+			odize.AssertNoError(t, err)
+			odize.AssertTrue(t, done)
+
+			odize.AssertTrue(t, inputs[0].ExclusiveStartKey == nil)
+			odize.AssertEqual(t, lastKey, inputs[1].ExclusiveStartKey)
+		}).
+		Test("iterator should return nil output once done", func(t *testing.T) {
+			next := QueryIterator(context.Background(), mockQuerier)
+
+			_, _, _ = next(&dynamodb.QueryInput{})
+			_, _, _ = next(&dynamodb.QueryInput{})
+			output, err, done := next(&dynamodb.QueryInput{})
+			odize.AssertNoError(t, err)
+			odize.AssertTrue(t, output == nil)
+			odize.AssertTrue(t, done)
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestScanIterator_startKey(t *testing.T) {
+	startKey := map[string]types.AttributeValue{
+		"key": &types.AttributeValueMemberS{Value: "start"},
+	}
+	lastKey := map[string]types.AttributeValue{
+		"key": &types.AttributeValueMemberS{Value: "last"},
+	}
+
+	var inputs []map[string]types.AttributeValue
+	scanner := &mockDDBScanner{
+		ScanFunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+			inputs = append(inputs, input.ExclusiveStartKey)
+			return &dynamodb.ScanOutput{LastEvaluatedKey: lastKey}, nil
+		},
+	}
+
+	next := ScanIterator(context.Background(), scanner)
+
+	_, err, _ := next(&dynamodb.ScanInput{ExclusiveStartKey: startKey})
+	odize.AssertNoError(t, err)
+	_, err, _ = next(&dynamodb.ScanInput{ExclusiveStartKey: startKey})
+	odize.AssertNoError(t, err)
+
+	odize.AssertEqual(t, startKey, inputs[0])
+	odize.AssertEqual(t, lastKey, inputs[1])
+}
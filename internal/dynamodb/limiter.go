@@ -0,0 +1,218 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CapacityLimit - a limit on the capacity units consumed per second.
+// The limit is either a number of units, or a percentage of the provisioned capacity of the table.
+type CapacityLimit struct {
+	Units   float64
+	Percent float64
+}
+
+// ParseCapacityLimit - parses a capacity limit of units e.g. "100", or a percentage of the provisioned capacity e.g. "50%".
+// An empty limit does not limit capacity.
+func ParseCapacityLimit(limit string) (CapacityLimit, error) {
+	limit = strings.TrimSpace(limit)
+	if limit == "" {
+		return CapacityLimit{}, nil
+	}
+
+	if percent, ok := strings.CutSuffix(limit, "%"); ok {
+		value, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil || value <= 0 || value > 100 || math.IsNaN(value) {
+			return CapacityLimit{}, fmt.Errorf("%w: percentage must be greater than 0 and at most 100, got %q", ErrInvalidCapacityLimit, limit)
+		}
+		return CapacityLimit{Percent: value}, nil
+	}
+
+	value, err := strconv.ParseFloat(limit, 64)
+	if err != nil || value <= 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return CapacityLimit{}, fmt.Errorf("%w: units must be greater than 0, got %q", ErrInvalidCapacityLimit, limit)
+	}
+
+	return CapacityLimit{Units: value}, nil
+}
+
+// IsSet - returns true if the limit limits capacity
+func (l CapacityLimit) IsSet() bool {
+	return l.Units > 0 || l.Percent > 0
+}
+
+// units - returns the units per second of the limit, percentages are resolved from the provisioned capacity
+func (l CapacityLimit) units(provisioned *int64) (float64, error) {
+	if l.Percent == 0 {
+		return l.Units, nil
+	}
+
+	if provisioned == nil || *provisioned == 0 {
+		return 0, fmt.Errorf("%w: a percentage limit requires a table with provisioned capacity", ErrInvalidCapacityLimit)
+	}
+
+	return float64(*provisioned) * l.Percent / 100, nil
+}
+
+// LimitCapacity - paces requests to the table so the consumed read and write capacity stays within the limits.
+// Percentage limits are resolved from the provisioned capacity of the table.
+//
+// Example:
+//
+//	err := client.LimitCapacity(ctx, "my-table", CapacityLimit{Percent: 50}, CapacityLimit{Units: 100})
+func (c *Client) LimitCapacity(ctx context.Context, tableName string, read CapacityLimit, write CapacityLimit) error {
+	var readUnits, writeUnits *int64
+
+	if read.Percent > 0 || write.Percent > 0 {
+		output, err := c.DescribeTable(ctx, &ddb.DescribeTableInput{TableName: &tableName})
+		if err != nil {
+			return err
+		}
+		if output.Table != nil && output.Table.ProvisionedThroughput != nil {
+			readUnits = output.Table.ProvisionedThroughput.ReadCapacityUnits
+			writeUnits = output.Table.ProvisionedThroughput.WriteCapacityUnits
+		}
+	}
+
+	if read.IsSet() {
+		units, err := read.units(readUnits)
+		if err != nil {
+			return fmt.Errorf("read capacity: %w", err)
+		}
+		c.logger.Debug("limiting read capacity", "table", tableName, "units", units)
+		c.readLimiter = newCapacityLimiter(units)
+	}
+
+	if write.IsSet() {
+		units, err := write.units(writeUnits)
+		if err != nil {
+			return fmt.Errorf("write capacity: %w", err)
+		}
+		c.logger.Debug("limiting write capacity", "table", tableName, "units", units)
+		c.writeLimiter = newCapacityLimiter(units)
+	}
+
+	return nil
+}
+
+// limit - waits for capacity before a request and, asks for the consumed capacity to be returned so it can be taken from the limiter
+func (c *Client) limit(ctx context.Context, limiter *capacityLimiter, returnCapacity *types.ReturnConsumedCapacity) error {
+	if limiter == nil {
+		return nil
+	}
+
+	*returnCapacity = types.ReturnConsumedCapacityTotal
+	return limiter.wait(ctx)
+}
+
+// capacityLimiter - a token bucket of capacity units, refilled at the limit per second.
+// Requests wait for the bucket to be positive and, the consumed capacity is taken once the response is known,
+// so a request that consumes more than is available delays the requests that follow.
+type capacityLimiter struct {
+	mx     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// newCapacityLimiter - creates a limiter of units per second, allowing a burst of one second of capacity
+func newCapacityLimiter(rate float64) *capacityLimiter {
+	return &capacityLimiter{
+		rate:   rate,
+		tokens: rate,
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// wait - blocks until capacity is available, a nil limiter does not wait
+func (l *capacityLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mx.Lock()
+		l.refill()
+		deficit := -l.tokens
+		l.mx.Unlock()
+
+		if deficit < 0 {
+			return nil
+		}
+
+		delay := time.Duration((deficit/l.rate)*float64(time.Second)) + time.Millisecond
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// consume - takes the consumed capacity units from the bucket, a nil limiter ignores consumed capacity
+func (l *capacityLimiter) consume(units float64) {
+	if l == nil {
+		return
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	l.refill()
+	l.tokens -= units
+}
+
+// refill - adds the capacity accrued since the last refill, up to one second of capacity
+func (l *capacityLimiter) refill() {
+	now := l.now()
+	l.tokens = math.Min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// sleepContext - sleeps for the duration, returning early with the context error if the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// consumedUnits - returns the total capacity units consumed, or the fallback when the response does not report consumed capacity
+func consumedUnits(consumed []types.ConsumedCapacity, fallback float64) float64 {
+	if len(consumed) == 0 {
+		return fallback
+	}
+
+	total := 0.0
+	for _, capacity := range consumed {
+		if capacity.CapacityUnits != nil {
+			total += *capacity.CapacityUnits
+		}
+	}
+
+	return total
+}
+
+// singleConsumed - returns the consumed capacity of a single table operation as a list, empty when it is not reported
+func singleConsumed(consumed *types.ConsumedCapacity) []types.ConsumedCapacity {
+	if consumed == nil {
+		return nil
+	}
+
+	return []types.ConsumedCapacity{*consumed}
+}
@@ -0,0 +1,186 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestParseCapacityLimit(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should parse units", func(t *testing.T) {
+			limit, err := ParseCapacityLimit("100")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, CapacityLimit{Units: 100}, limit)
+		}).
+		Test("should parse a percentage", func(t *testing.T) {
+			limit, err := ParseCapacityLimit("50%")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, CapacityLimit{Percent: 50}, limit)
+		}).
+		Test("should not limit an empty limit", func(t *testing.T) {
+			limit, err := ParseCapacityLimit("")
+			odize.AssertNoError(t, err)
+			odize.AssertFalse(t, limit.IsSet())
+		}).
+		Test("should return error for a percentage over 100", func(t *testing.T) {
+			_, err := ParseCapacityLimit("150%")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCapacityLimit))
+		}).
+		Test("should return error for zero units", func(t *testing.T) {
+			_, err := ParseCapacityLimit("0")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCapacityLimit))
+		}).
+		Test("should return error for an invalid limit", func(t *testing.T) {
+			_, err := ParseCapacityLimit("lots")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCapacityLimit))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestCapacityLimiter(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var limiter *capacityLimiter
+	var now time.Time
+	var slept []time.Duration
+
+	group.BeforeEach(func() {
+		now = time.Now()
+		slept = nil
+		limiter = newCapacityLimiter(10)
+		limiter.last = now
+		limiter.now = func() time.Time { return now }
+		limiter.sleep = func(ctx context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			now = now.Add(d)
+			return ctx.Err()
+		}
+	})
+
+	err := group.
+		Test("should not wait while capacity is available", func(t *testing.T) {
+			limiter.consume(5)
+			odize.AssertNoError(t, limiter.wait(context.Background()))
+			odize.AssertEqual(t, 0, len(slept))
+		}).
+		Test("should wait for consumed capacity to be refilled", func(t *testing.T) {
+			limiter.consume(25)
+			odize.AssertNoError(t, limiter.wait(context.Background()))
+
+			odize.AssertEqual(t, 1, len(slept))
+			odize.AssertEqual(t, 1500*time.Millisecond+time.Millisecond, slept[0])
+		}).
+		Test("should not refill more than a second of capacity", func(t *testing.T) {
+			now = now.Add(time.Minute)
+			limiter.consume(15)
+			odize.AssertNoError(t, limiter.wait(context.Background()))
+			odize.AssertEqual(t, 1, len(slept))
+		}).
+		Test("should stop waiting when the context is cancelled", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			limiter.consume(25)
+			odize.AssertTrue(t, errors.Is(limiter.wait(ctx), context.Canceled))
+		}).
+		Test("should not wait on a nil limiter", func(t *testing.T) {
+			var nilLimiter *capacityLimiter
+			nilLimiter.consume(100)
+			odize.AssertNoError(t, nilLimiter.wait(context.Background()))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestClient_LimitCapacity(t *testing.T) {
+	logger := logging.New(false)
+	ctx := logging.WithContext(context.Background(), logger)
+	var client Client
+	var db ddbClientMock
+
+	group := odize.NewGroup(t, nil)
+	group.BeforeEach(func() {
+		db = ddbClientMock{
+			DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+				return &dynamodb.DescribeTableOutput{
+					Table: &types.TableDescription{
+						ProvisionedThroughput: &types.ProvisionedThroughputDescription{
+							ReadCapacityUnits:  aws.Int64(200),
+							WriteCapacityUnits: aws.Int64(0),
+						},
+					},
+				}, nil
+			},
+			ScanFunc: func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{
+					ConsumedCapacity: &types.ConsumedCapacity{CapacityUnits: aws.Float64(30)},
+				}, nil
+			},
+			BatchWriteItemFunc: func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			},
+		}
+
+		client = Client{
+			logger: logger,
+			db:     &db,
+		}
+	})
+
+	err := group.
+		Test("should resolve a percentage of the provisioned capacity", func(t *testing.T) {
+			err := client.LimitCapacity(ctx, "my-table", CapacityLimit{Percent: 25}, CapacityLimit{Units: 10})
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 50.0, client.readLimiter.rate)
+			odize.AssertEqual(t, 10.0, client.writeLimiter.rate)
+		}).
+		Test("should not describe the table for unit limits", func(t *testing.T) {
+			err := client.LimitCapacity(ctx, "my-table", CapacityLimit{Units: 5}, CapacityLimit{})
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 0, len(db.DescribeTableCalls()))
+			odize.AssertTrue(t, client.writeLimiter == nil)
+		}).
+		Test("should return error for a percentage of on demand capacity", func(t *testing.T) {
+			err := client.LimitCapacity(ctx, "my-table", CapacityLimit{}, CapacityLimit{Percent: 50})
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCapacityLimit))
+		}).
+		Test("should take the consumed read capacity from the limiter", func(t *testing.T) {
+			err := client.LimitCapacity(ctx, "my-table", CapacityLimit{Units: 100}, CapacityLimit{})
+			odize.AssertNoError(t, err)
+
+			input := dynamodb.ScanInput{}
+			_, err = client.Scan(ctx, &input)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, types.ReturnConsumedCapacityTotal, input.ReturnConsumedCapacity)
+			odize.AssertTrue(t, client.readLimiter.tokens < 71)
+		}).
+		Test("should take a unit per write request when consumed capacity is not reported", func(t *testing.T) {
+			err := client.LimitCapacity(ctx, "my-table", CapacityLimit{}, CapacityLimit{Units: 100})
+			odize.AssertNoError(t, err)
+
+			_, err = client.BatchPutItems(ctx, "my-table", []map[string]types.AttributeValue{
+				{"pk": &types.AttributeValueMemberS{Value: "1"}},
+				{"pk": &types.AttributeValueMemberS{Value: "2"}},
+			})
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, types.ReturnConsumedCapacityTotal, db.BatchWriteItemCalls()[0].Params.ReturnConsumedCapacity)
+			odize.AssertTrue(t, client.writeLimiter.tokens < 99)
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
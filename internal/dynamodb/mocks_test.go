@@ -22,9 +22,15 @@ var _ ddbClient = &ddbClientMock{}
 //			BatchWriteItemFunc: func(ctx context.Context, params *ddb.BatchWriteItemInput, optFns ...func(*ddb.Options)) (*ddb.BatchWriteItemOutput, error) {
 //				panic("mock out the BatchWriteItem method")
 //			},
+//			DescribeTableFunc: func(ctx context.Context, params *ddb.DescribeTableInput, optFns ...func(*ddb.Options)) (*ddb.DescribeTableOutput, error) {
+//				panic("mock out the DescribeTable method")
+//			},
 //			PutItemFunc: func(ctx context.Context, params *ddb.PutItemInput, optFns ...func(*ddb.Options)) (*ddb.PutItemOutput, error) {
 //				panic("mock out the PutItem method")
 //			},
+//			QueryFunc: func(ctx context.Context, params *ddb.QueryInput, optFns ...func(*ddb.Options)) (*ddb.QueryOutput, error) {
+//				panic("mock out the Query method")
+//			},
 //			ScanFunc: func(ctx context.Context, params *ddb.ScanInput, optFns ...func(*ddb.Options)) (*ddb.ScanOutput, error) {
 //				panic("mock out the Scan method")
 //			},
@@ -38,9 +44,15 @@ type ddbClientMock struct {
 	// BatchWriteItemFunc mocks the BatchWriteItem method.
 	BatchWriteItemFunc func(ctx context.Context, params *ddb.BatchWriteItemInput, optFns ...func(*ddb.Options)) (*ddb.BatchWriteItemOutput, error)
 
+	// DescribeTableFunc mocks the DescribeTable method.
+	DescribeTableFunc func(ctx context.Context, params *ddb.DescribeTableInput, optFns ...func(*ddb.Options)) (*ddb.DescribeTableOutput, error)
+
 	// PutItemFunc mocks the PutItem method.
 	PutItemFunc func(ctx context.Context, params *ddb.PutItemInput, optFns ...func(*ddb.Options)) (*ddb.PutItemOutput, error)
 
+	// QueryFunc mocks the Query method.
+	QueryFunc func(ctx context.Context, params *ddb.QueryInput, optFns ...func(*ddb.Options)) (*ddb.QueryOutput, error)
+
 	// ScanFunc mocks the Scan method.
 	ScanFunc func(ctx context.Context, params *ddb.ScanInput, optFns ...func(*ddb.Options)) (*ddb.ScanOutput, error)
 
@@ -55,6 +67,15 @@ type ddbClientMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*ddb.Options)
 		}
+		// DescribeTable holds details about calls to the DescribeTable method.
+		DescribeTable []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *ddb.DescribeTableInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*ddb.Options)
+		}
 		// PutItem holds details about calls to the PutItem method.
 		PutItem []struct {
 			// Ctx is the ctx argument value.
@@ -64,6 +85,15 @@ type ddbClientMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*ddb.Options)
 		}
+		// Query holds details about calls to the Query method.
+		Query []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *ddb.QueryInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*ddb.Options)
+		}
 		// Scan holds details about calls to the Scan method.
 		Scan []struct {
 			// Ctx is the ctx argument value.
@@ -75,7 +105,9 @@ type ddbClientMock struct {
 		}
 	}
 	lockBatchWriteItem sync.RWMutex
+	lockDescribeTable  sync.RWMutex
 	lockPutItem        sync.RWMutex
+	lockQuery          sync.RWMutex
 	lockScan           sync.RWMutex
 }
 
@@ -123,6 +155,50 @@ func (mock *ddbClientMock) BatchWriteItemCalls() []struct {
 	return calls
 }
 
+// DescribeTable calls DescribeTableFunc.
+func (mock *ddbClientMock) DescribeTable(ctx context.Context, params *ddb.DescribeTableInput, optFns ...func(*ddb.Options)) (*ddb.DescribeTableOutput, error) {
+	callInfo := struct {
+		Ctx    context.Context
+		Params *ddb.DescribeTableInput
+		OptFns []func(*ddb.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockDescribeTable.Lock()
+	mock.calls.DescribeTable = append(mock.calls.DescribeTable, callInfo)
+	mock.lockDescribeTable.Unlock()
+	if mock.DescribeTableFunc == nil {
+		var (
+			describeTableOutputOut *ddb.DescribeTableOutput
+			errOut                 error
+		)
+		return describeTableOutputOut, errOut
+	}
+	return mock.DescribeTableFunc(ctx, params, optFns...)
+}
+
+// DescribeTableCalls gets all the calls that were made to DescribeTable.
+// Check the length with:
+//
+//	len(mockedddbClient.DescribeTableCalls())
+func (mock *ddbClientMock) DescribeTableCalls() []struct {
+	Ctx    context.Context
+	Params *ddb.DescribeTableInput
+	OptFns []func(*ddb.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *ddb.DescribeTableInput
+		OptFns []func(*ddb.Options)
+	}
+	mock.lockDescribeTable.RLock()
+	calls = mock.calls.DescribeTable
+	mock.lockDescribeTable.RUnlock()
+	return calls
+}
+
 // PutItem calls PutItemFunc.
 func (mock *ddbClientMock) PutItem(ctx context.Context, params *ddb.PutItemInput, optFns ...func(*ddb.Options)) (*ddb.PutItemOutput, error) {
 	callInfo := struct {
@@ -167,6 +243,50 @@ func (mock *ddbClientMock) PutItemCalls() []struct {
 	return calls
 }
 
+// Query calls QueryFunc.
+func (mock *ddbClientMock) Query(ctx context.Context, params *ddb.QueryInput, optFns ...func(*ddb.Options)) (*ddb.QueryOutput, error) {
+	callInfo := struct {
+		Ctx    context.Context
+		Params *ddb.QueryInput
+		OptFns []func(*ddb.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockQuery.Lock()
+	mock.calls.Query = append(mock.calls.Query, callInfo)
+	mock.lockQuery.Unlock()
+	if mock.QueryFunc == nil {
+		var (
+			queryOutputOut *ddb.QueryOutput
+			errOut         error
+		)
+		return queryOutputOut, errOut
+	}
+	return mock.QueryFunc(ctx, params, optFns...)
+}
+
+// QueryCalls gets all the calls that were made to Query.
+// Check the length with:
+//
+//	len(mockedddbClient.QueryCalls())
+func (mock *ddbClientMock) QueryCalls() []struct {
+	Ctx    context.Context
+	Params *ddb.QueryInput
+	OptFns []func(*ddb.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *ddb.QueryInput
+		OptFns []func(*ddb.Options)
+	}
+	mock.lockQuery.RLock()
+	calls = mock.calls.Query
+	mock.lockQuery.RUnlock()
+	return calls
+}
+
 // Scan calls ScanFunc.
 func (mock *ddbClientMock) Scan(ctx context.Context, params *ddb.ScanInput, optFns ...func(*ddb.Options)) (*ddb.ScanOutput, error) {
 	callInfo := struct {
@@ -0,0 +1,96 @@
+package dynamodb
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DefaultRetryPolicy - the retry policy used for batch operations when none is set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 10,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// RetryPolicy - controls how unprocessed items of a batch operation are retried.
+// Retries back off exponentially from the base delay up to the max delay, with full jitter.
+type RetryPolicy struct {
+	// MaxAttempts - the maximum number of requests made for a batch, including the first request
+	MaxAttempts int
+	// BaseDelay - the delay before the first retry, doubled for each retry after
+	BaseDelay time.Duration
+	// MaxDelay - the maximum delay between retries
+	MaxDelay time.Duration
+}
+
+// SetRetryPolicy - sets the retry policy used for batch operations, zero fields use the default retry policy
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// withDefaults - returns the policy with zero fields set from the default retry policy
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.MaxDelay < p.BaseDelay {
+		p.MaxDelay = p.BaseDelay
+	}
+
+	return p
+}
+
+// delay - returns a random delay between zero and the exponential backoff of the retry, capped at the max delay
+func (p RetryPolicy) delay(retry int) time.Duration {
+	backoff := p.MaxDelay
+	if retry < 32 {
+		backoff = min(p.MaxDelay, p.BaseDelay<<retry)
+	}
+	if backoff <= 0 {
+		backoff = p.MaxDelay
+	}
+
+	return time.Duration(rand.Int64N(int64(backoff) + 1))
+}
+
+// UnprocessedItemsError - items of a batch operation that were still unprocessed once the retries were exhausted
+type UnprocessedItemsError struct {
+	Attempts int
+	// Items - the unprocessed write requests by table name
+	Items map[string][]types.WriteRequest
+}
+
+func (e *UnprocessedItemsError) Error() string {
+	return fmt.Sprintf("%s: %d items after %d attempts", ErrUnprocessedItems, len(e.Keys()), e.Attempts)
+}
+
+func (e *UnprocessedItemsError) Unwrap() error {
+	return ErrUnprocessedItems
+}
+
+// Keys - returns the keys of the unprocessed delete requests, and the items of the unprocessed put requests
+func (e *UnprocessedItemsError) Keys() []map[string]types.AttributeValue {
+	keys := []map[string]types.AttributeValue{}
+
+	for _, requests := range e.Items {
+		for _, request := range requests {
+			switch {
+			case request.DeleteRequest != nil:
+				keys = append(keys, request.DeleteRequest.Key)
+			case request.PutRequest != nil:
+				keys = append(keys, request.PutRequest.Item)
+			}
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,141 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should default zero fields", func(t *testing.T) {
+			policy := RetryPolicy{MaxAttempts: 3}.withDefaults()
+			odize.AssertEqual(t, 3, policy.MaxAttempts)
+			odize.AssertEqual(t, DefaultRetryPolicy.BaseDelay, policy.BaseDelay)
+			odize.AssertEqual(t, DefaultRetryPolicy.MaxDelay, policy.MaxDelay)
+		}).
+		Test("should not allow a max delay below the base delay", func(t *testing.T) {
+			policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Millisecond}.withDefaults()
+			odize.AssertEqual(t, time.Second, policy.MaxDelay)
+		}).
+		Test("should jitter within the exponential backoff", func(t *testing.T) {
+			policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}
+			for range 100 {
+				odize.AssertTrue(t, policy.delay(0) <= 10*time.Millisecond)
+				odize.AssertTrue(t, policy.delay(3) <= 80*time.Millisecond)
+			}
+		}).
+		Test("should cap the backoff at the max delay", func(t *testing.T) {
+			policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+			for range 100 {
+				odize.AssertTrue(t, policy.delay(10) <= 50*time.Millisecond)
+				odize.AssertTrue(t, policy.delay(100) <= 50*time.Millisecond)
+			}
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func TestClient_batchWriteRetry(t *testing.T) {
+	logger := logging.New(false)
+	ctx := logging.WithContext(context.Background(), logger)
+	var client Client
+	var db ddbClientMock
+	var delays []time.Duration
+
+	key := map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "value"},
+	}
+	unprocessed := map[string][]types.WriteRequest{
+		"table": {{DeleteRequest: &types.DeleteRequest{Key: key}}},
+	}
+
+	group := odize.NewGroup(t, nil)
+	group.BeforeEach(func() {
+		delays = nil
+		db = ddbClientMock{
+			BatchWriteItemFunc: func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{UnprocessedItems: unprocessed}, nil
+			},
+		}
+
+		client = Client{
+			logger: logger,
+			db:     &db,
+			sleep: func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			},
+		}
+		client.SetRetryPolicy(RetryPolicy{
+			MaxAttempts: 4,
+			BaseDelay:   10 * time.Millisecond,
+			MaxDelay:    15 * time.Millisecond,
+		})
+	})
+
+	err := group.
+		Test("should stop after the max attempts", func(t *testing.T) {
+			_, err := client.BatchDeleteItems(ctx, "table", []map[string]types.AttributeValue{key})
+			odize.AssertTrue(t, errors.Is(err, ErrUnprocessedItems))
+
+			odize.AssertEqual(t, 4, len(db.BatchWriteItemCalls()))
+			odize.AssertEqual(t, 3, len(delays))
+		}).
+		Test("should list the unprocessed keys", func(t *testing.T) {
+			_, err := client.BatchDeleteItems(ctx, "table", []map[string]types.AttributeValue{key})
+
+			var unprocessedErr *UnprocessedItemsError
+			odize.AssertTrue(t, errors.As(err, &unprocessedErr))
+			odize.AssertEqual(t, 4, unprocessedErr.Attempts)
+			odize.AssertEqual(t, []map[string]types.AttributeValue{key}, unprocessedErr.Keys())
+		}).
+		Test("should list the unprocessed put items", func(t *testing.T) {
+			db.BatchWriteItemFunc = func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{UnprocessedItems: params.RequestItems}, nil
+			}
+
+			_, err := client.BatchPutItems(ctx, "table", []map[string]types.AttributeValue{key})
+
+			var unprocessedErr *UnprocessedItemsError
+			odize.AssertTrue(t, errors.As(err, &unprocessedErr))
+			odize.AssertEqual(t, []map[string]types.AttributeValue{key}, unprocessedErr.Keys())
+		}).
+		Test("should back off within the max delay", func(t *testing.T) {
+			_, _ = client.BatchDeleteItems(ctx, "table", []map[string]types.AttributeValue{key})
+
+			odize.AssertTrue(t, delays[0] <= 10*time.Millisecond)
+			odize.AssertTrue(t, delays[1] <= 15*time.Millisecond)
+			odize.AssertTrue(t, delays[2] <= 15*time.Millisecond)
+		}).
+		Test("should return the context error while backing off", func(t *testing.T) {
+			client.sleep = func(ctx context.Context, d time.Duration) error {
+				return context.Canceled
+			}
+
+			_, err := client.BatchDeleteItems(ctx, "table", []map[string]types.AttributeValue{key})
+			odize.AssertTrue(t, errors.Is(err, context.Canceled))
+			odize.AssertEqual(t, 1, len(db.BatchWriteItemCalls()))
+		}).
+		Test("should not back off once all items are processed", func(t *testing.T) {
+			db.BatchWriteItemFunc = func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			}
+
+			_, err := client.BatchDeleteItems(ctx, "table", []map[string]types.AttributeValue{key})
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, 0, len(delays))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
@@ -1,19 +1,41 @@
 package dynamodb
 
 import (
+	"context"
 	"errors"
 	"log/slog"
+	"time"
 )
 
 var (
-	ErrNoItems = errors.New("no items found")
+	ErrNoItems               = errors.New("no items found")
+	ErrInvalidAttributeValue = errors.New("invalid attribute value")
+	ErrInvalidNumberMode     = errors.New("invalid number mode")
+	ErrInvalidCapacityLimit  = errors.New("invalid capacity limit")
+	ErrUnprocessedItems      = errors.New("unprocessed items")
+)
+
+// NumberMode - controls how number attributes are represented when flattening attribute values
+type NumberMode string
+
+const (
+	// NumberModeFloat - parse numbers as float64, precision may be lost for large or high precision numbers
+	NumberModeFloat NumberMode = "float"
+	// NumberModeString - return the numeric text as a string
+	NumberModeString NumberMode = "string"
+	// NumberModeExact - return the numeric text as a json.Number, encoded as a json number without loss of precision
+	NumberModeExact NumberMode = "exact"
 )
 
 // Client - dynamodb client to query the table (get,put,query,scan)
 type Client struct {
-	db     ddbClient
-	logger *slog.Logger
-	dryRun bool
+	db           ddbClient
+	logger       *slog.Logger
+	dryRun       bool
+	readLimiter  *capacityLimiter
+	writeLimiter *capacityLimiter
+	retryPolicy  RetryPolicy
+	sleep        func(ctx context.Context, d time.Duration) error
 }
 
 type AVer interface {
@@ -1,7 +1,10 @@
 package dynamodb
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -17,12 +20,27 @@ func JSONStringify(v any) string {
 	return string(data)
 }
 
+// ParseNumberMode - parses the given number mode, returning ErrInvalidNumberMode if it is not supported
+func ParseNumberMode(mode string) (NumberMode, error) {
+	switch NumberMode(mode) {
+	case NumberModeFloat, NumberModeString, NumberModeExact:
+		return NumberMode(mode), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrInvalidNumberMode, mode)
+}
+
 // FlattenAttrList - flattens the given attribute value list.
 func FlattenAttrList(data []map[string]types.AttributeValue) ([]map[string]any, error) {
+	return FlattenAttrListWithMode(data, NumberModeFloat)
+}
+
+// FlattenAttrListWithMode - flattens the given attribute value list, representing numbers with the given number mode.
+func FlattenAttrListWithMode(data []map[string]types.AttributeValue, mode NumberMode) ([]map[string]any, error) {
 	transformed := []map[string]any{}
 
 	for _, item := range data {
-		transformedItem, err := FlattenAttrValue(item)
+		transformedItem, err := FlattenAttrValueWithMode(item, mode)
 		if err != nil {
 			return nil, err
 		}
@@ -36,10 +54,15 @@ func FlattenAttrList(data []map[string]types.AttributeValue) ([]map[string]any,
 // FlattenAttrValue - flattens the given attribute value map.
 // Removes the "Value" attribute from the AttributeValueMember struct and returns the value as a map[string]any.
 func FlattenAttrValue(data map[string]types.AttributeValue) (map[string]any, error) {
+	return FlattenAttrValueWithMode(data, NumberModeFloat)
+}
+
+// FlattenAttrValueWithMode - flattens the given attribute value map, representing numbers with the given number mode.
+func FlattenAttrValueWithMode(data map[string]types.AttributeValue, mode NumberMode) (map[string]any, error) {
 	transformed := map[string]any{}
 
 	for key, value := range data {
-		transformedValue, err := extractAttrValue(value)
+		transformedValue, err := extractAttrValue(value, mode)
 		if err != nil {
 			return nil, err
 		}
@@ -50,13 +73,13 @@ func FlattenAttrValue(data map[string]types.AttributeValue) (map[string]any, err
 	return transformed, nil
 }
 
-func extractAttrValue(value types.AttributeValue) (any, error) {
+func extractAttrValue(value types.AttributeValue, mode NumberMode) (any, error) {
 	var returnVal any
 	switch v := value.(type) {
 	case *types.AttributeValueMemberS:
 		returnVal = v.Value
 	case *types.AttributeValueMemberN:
-		parsed, err := strconv.ParseFloat(v.Value, 64)
+		parsed, err := extractNumber(v.Value, mode)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +94,7 @@ func extractAttrValue(value types.AttributeValue) (any, error) {
 		var err error
 		result := map[string]any{}
 		for key, value := range v.Value {
-			result[key], err = extractAttrValue(value)
+			result[key], err = extractAttrValue(value, mode)
 			if err != nil {
 				return nil, err
 			}
@@ -80,7 +103,7 @@ func extractAttrValue(value types.AttributeValue) (any, error) {
 	case *types.AttributeValueMemberL:
 		result := []any{}
 		for _, item := range v.Value {
-			transformedItem, err := extractAttrValue(item)
+			transformedItem, err := extractAttrValue(item, mode)
 			if err != nil {
 				return nil, err
 			}
@@ -99,6 +122,18 @@ func extractAttrValue(value types.AttributeValue) (any, error) {
 	return returnVal, nil
 }
 
+// extractNumber - converts the numeric text of a N attribute using the given number mode
+func extractNumber(value string, mode NumberMode) (any, error) {
+	switch mode {
+	case NumberModeString:
+		return value, nil
+	case NumberModeExact:
+		return json.Number(value), nil
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
 func ConvertAVValues(data []map[string]types.AttributeValue) ([]map[string]AVer, error) {
 	transformed := []map[string]AVer{}
 
@@ -193,3 +228,183 @@ func convertAVValue(value types.AttributeValue) (AVer, error) {
 
 	return returnVal, nil
 }
+
+// IsAVItem - reports whether every attribute of the item is in the dynamodb json format produced by ConvertAVValue.
+//
+// Example:
+//
+//	IsAVItem(map[string]any{"pk": map[string]any{"S": "value"}}) // true
+func IsAVItem(data map[string]any) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	for _, value := range data {
+		if _, _, ok := avDescriptor(value); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseAVValue - parses an item in the dynamodb json format back into attribute values.
+// This is the inverse of ConvertAVValue, once the item has been encoded and decoded as json.
+func ParseAVValue(data map[string]any) (map[string]types.AttributeValue, error) {
+	transformed := map[string]types.AttributeValue{}
+
+	for key, value := range data {
+		transformedValue, err := parseAVValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", key, err)
+		}
+
+		transformed[key] = transformedValue
+	}
+
+	return transformed, nil
+}
+
+func parseAVValue(value any) (types.AttributeValue, error) {
+	descriptor, v, ok := avDescriptor(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a single type descriptor, got %v", ErrInvalidAttributeValue, value)
+	}
+
+	switch descriptor {
+	case "S":
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: S must be a string", ErrInvalidAttributeValue)
+		}
+		return &types.AttributeValueMemberS{Value: str}, nil
+	case "N":
+		num, ok := avNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("%w: N must be a number string", ErrInvalidAttributeValue)
+		}
+		return &types.AttributeValueMemberN{Value: num}, nil
+	case "B":
+		b, err := avBytes(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: B %w", ErrInvalidAttributeValue, err)
+		}
+		return &types.AttributeValueMemberB{Value: b}, nil
+	case "BOOL":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: BOOL must be a boolean", ErrInvalidAttributeValue)
+		}
+		return &types.AttributeValueMemberBOOL{Value: b}, nil
+	case "NULL":
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case "M":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: M must be an object", ErrInvalidAttributeValue)
+		}
+		result, err := ParseAVValue(m)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: result}, nil
+	case "L":
+		l, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: L must be a list", ErrInvalidAttributeValue)
+		}
+		result := []types.AttributeValue{}
+		for _, item := range l {
+			transformedItem, err := parseAVValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, transformedItem)
+		}
+		return &types.AttributeValueMemberL{Value: result}, nil
+	case "SS":
+		l, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: SS must be a list", ErrInvalidAttributeValue)
+		}
+		result := []string{}
+		for _, item := range l {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: SS must only contain strings", ErrInvalidAttributeValue)
+			}
+			result = append(result, str)
+		}
+		return &types.AttributeValueMemberSS{Value: result}, nil
+	case "NS":
+		l, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: NS must be a list", ErrInvalidAttributeValue)
+		}
+		result := []string{}
+		for _, item := range l {
+			num, ok := avNumber(item)
+			if !ok {
+				return nil, fmt.Errorf("%w: NS must only contain number strings", ErrInvalidAttributeValue)
+			}
+			result = append(result, num)
+		}
+		return &types.AttributeValueMemberNS{Value: result}, nil
+	case "BS":
+		l, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: BS must be a list", ErrInvalidAttributeValue)
+		}
+		result := [][]byte{}
+		for _, item := range l {
+			b, err := avBytes(item)
+			if err != nil {
+				return nil, fmt.Errorf("%w: BS %w", ErrInvalidAttributeValue, err)
+			}
+			result = append(result, b)
+		}
+		return &types.AttributeValueMemberBS{Value: result}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown type descriptor %s", ErrInvalidAttributeValue, descriptor)
+}
+
+// avDescriptor - returns the type descriptor and value of a dynamodb json attribute, e.g. {"S": "value"}
+func avDescriptor(value any) (string, any, bool) {
+	m, ok := value.(map[string]any)
+	if !ok || len(m) != 1 {
+		return "", nil, false
+	}
+
+	for descriptor, v := range m {
+		switch descriptor {
+		case "S", "N", "B", "BOOL", "NULL", "M", "L", "SS", "NS", "BS":
+			return descriptor, v, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// avNumber - returns the numeric text of a N value, accepting both strings and json numbers
+func avNumber(v any) (string, bool) {
+	switch n := v.(type) {
+	case string:
+		return n, n != ""
+	case json.Number:
+		return n.String(), true
+	}
+
+	return "", false
+}
+
+// avBytes - decodes a base64 encoded B value, as written by encoding/json for []byte
+func avBytes(v any) ([]byte, error) {
+	str, ok := v.(string)
+	if !ok {
+		return nil, errors.New("must be a base64 string")
+	}
+
+	return base64.StdEncoding.DecodeString(str)
+}
@@ -1,6 +1,8 @@
 package dynamodb
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -78,3 +80,110 @@ func Test_transformJSON(t *testing.T) {
 		Run()
 	odize.AssertNoError(t, err)
 }
+
+func TestParseAVValue(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	roundTrip := func(t *testing.T, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+		converted, err := ConvertAVValue(item)
+		odize.AssertNoError(t, err)
+
+		data, err := json.Marshal(converted)
+		odize.AssertNoError(t, err)
+
+		var decoded map[string]any
+		odize.AssertNoError(t, json.Unmarshal(data, &decoded))
+		odize.AssertTrue(t, IsAVItem(decoded))
+
+		parsed, err := ParseAVValue(decoded)
+		odize.AssertNoError(t, err)
+
+		return parsed
+	}
+
+	err := group.
+		Test("should round trip scalar values", func(t *testing.T) {
+			item := map[string]types.AttributeValue{
+				"s":    &types.AttributeValueMemberS{Value: "value"},
+				"n":    &types.AttributeValueMemberN{Value: "12345678901234567890.123"},
+				"b":    &types.AttributeValueMemberB{Value: []byte("bytes")},
+				"bool": &types.AttributeValueMemberBOOL{Value: true},
+				"null": &types.AttributeValueMemberNULL{Value: true},
+			}
+
+			odize.AssertEqual(t, item, roundTrip(t, item))
+		}).
+		Test("should round trip sets", func(t *testing.T) {
+			item := map[string]types.AttributeValue{
+				"ss": &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+				"ns": &types.AttributeValueMemberNS{Value: []string{"1", "2.5"}},
+				"bs": &types.AttributeValueMemberBS{Value: [][]byte{[]byte("a"), []byte("b")}},
+			}
+
+			odize.AssertEqual(t, item, roundTrip(t, item))
+		}).
+		Test("should round trip nested maps and lists", func(t *testing.T) {
+			item := map[string]types.AttributeValue{
+				"m": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: "value"},
+						&types.AttributeValueMemberN{Value: "1"},
+					}},
+				}},
+			}
+
+			odize.AssertEqual(t, item, roundTrip(t, item))
+		}).
+		Test("should not detect flattened items", func(t *testing.T) {
+			odize.AssertFalse(t, IsAVItem(map[string]any{"pk": "value"}))
+			odize.AssertFalse(t, IsAVItem(map[string]any{"pk": map[string]any{"name": "value"}}))
+		}).
+		Test("should return error on invalid value", func(t *testing.T) {
+			_, err := ParseAVValue(map[string]any{"pk": map[string]any{"N": true}})
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidAttributeValue))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestFlattenAttrValueWithMode(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	example := map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberN{Value: "9007199254740993"},
+		"nested": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberN{Value: "0.10000000000000000001"},
+		}},
+	}
+
+	err := group.
+		Test("should keep exact numeric text", func(t *testing.T) {
+			d, err := FlattenAttrValueWithMode(example, NumberModeExact)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, json.Number("9007199254740993"), d["id"])
+			odize.AssertEqual(t, json.Number("0.10000000000000000001"), d["nested"].([]any)[0])
+
+			data, err := json.Marshal(d)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, `{"id":9007199254740993,"nested":[0.10000000000000000001]}`, string(data))
+		}).
+		Test("should return numbers as strings", func(t *testing.T) {
+			d, err := FlattenAttrValueWithMode(example, NumberModeString)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, "9007199254740993", d["id"])
+		}).
+		Test("should return numbers as float", func(t *testing.T) {
+			d, err := FlattenAttrValueWithMode(example, NumberModeFloat)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, float64(9007199254740993), d["id"])
+		}).
+		Test("should return error on unknown number mode", func(t *testing.T) {
+			_, err := ParseNumberMode("decimal")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidNumberMode))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
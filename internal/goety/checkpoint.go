@@ -0,0 +1,309 @@
+package goety
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+)
+
+var (
+	ErrNoCheckpoint       = errors.New("no checkpoint found")
+	ErrCheckpointMismatch = errors.New("checkpoint does not match the requested operation")
+)
+
+// Checkpoint - progress of a scan or query, used to resume an operation that did not complete.
+// The format, filter and partition are recorded so the operation is only resumed with the same options.
+type Checkpoint struct {
+	TableName     string                       `json:"tableName"`
+	TotalSegments int32                        `json:"totalSegments"`
+	Format        Format                       `json:"format,omitempty"`
+	Filter        string                       `json:"filter,omitempty"`
+	Partition     string                       `json:"partition,omitempty"`
+	SortCondition *SortCondition               `json:"sortCondition,omitempty"`
+	Items         int                          `json:"items"`
+	Offset        int64                        `json:"offset"`
+	Segments      map[int32]*SegmentCheckpoint `json:"segments"`
+}
+
+// SegmentCheckpoint - progress of a single segment, the last evaluated key is stored as dynamodb json
+type SegmentCheckpoint struct {
+	LastEvaluatedKey map[string]any `json:"lastEvaluatedKey,omitempty"`
+	Done             bool           `json:"done"`
+}
+
+// newCheckpoint - creates an empty checkpoint for the table and the query options
+func newCheckpoint(tableName string, queryOpts *QueryOpts) (*Checkpoint, error) {
+	filter, err := queryDigest(queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Checkpoint{
+		TableName:     tableName,
+		TotalSegments: max(queryOpts.Segments, 1),
+		Format:        queryOpts.Format,
+		Filter:        filter,
+		Partition:     aws.ToString(queryOpts.Partition),
+		SortCondition: queryOpts.SortCondition,
+		Segments:      map[int32]*SegmentCheckpoint{},
+	}, nil
+}
+
+// validate - ensures the checkpoint was created for the same table, number of segments, format, filter and partition as the expected checkpoint
+func (c *Checkpoint) validate(expected *Checkpoint) error {
+	if c.TableName != expected.TableName {
+		return fmt.Errorf("%w: checkpoint table %s, table %s", ErrCheckpointMismatch, c.TableName, expected.TableName)
+	}
+
+	if c.TotalSegments != expected.TotalSegments {
+		return fmt.Errorf("%w: checkpoint segments %d, segments %d", ErrCheckpointMismatch, c.TotalSegments, expected.TotalSegments)
+	}
+
+	if c.Format != expected.Format {
+		return fmt.Errorf("%w: checkpoint format %q, format %q", ErrCheckpointMismatch, c.Format, expected.Format)
+	}
+
+	if c.Filter != expected.Filter {
+		return fmt.Errorf("%w: the filter, projection or key condition has changed", ErrCheckpointMismatch)
+	}
+
+	if c.Partition != expected.Partition || !sameSortCondition(c.SortCondition, expected.SortCondition) {
+		return fmt.Errorf("%w: the partition or sort condition has changed", ErrCheckpointMismatch)
+	}
+
+	if c.Segments == nil {
+		c.Segments = map[int32]*SegmentCheckpoint{}
+	}
+
+	return nil
+}
+
+// sameSortCondition - returns whether the sort conditions have the same operator and values
+func sameSortCondition(a *SortCondition, b *SortCondition) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Operator == b.Operator && slices.Equal(a.Values, b.Values)
+}
+
+// queryDigest - returns a digest of the key condition, index, filter and projection of the query, with their attribute names and values.
+// Returns an empty digest when the query has none of them.
+func queryDigest(queryOpts *QueryOpts) (string, error) {
+	exprs, err := buildExpressions(queryOpts)
+	if err != nil {
+		return "", err
+	}
+
+	if queryOpts.KeyConditionExpression == nil && queryOpts.IndexName == nil && exprs.filter == nil && exprs.projection == nil && len(exprs.names) == 0 && len(exprs.values) == 0 {
+		return "", nil
+	}
+
+	values, err := ddb.ConvertAVValue(exprs.values)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"keyCondition": aws.ToString(queryOpts.KeyConditionExpression),
+		"index":        aws.ToString(queryOpts.IndexName),
+		"filter":       aws.ToString(exprs.filter),
+		"projection":   aws.ToString(exprs.projection),
+		"names":        exprs.names,
+		"values":       values,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// update - records the progress of a segment, the segment is done when there is no last evaluated key
+func (c *Checkpoint) update(segment int32, lastEvaluatedKey map[string]types.AttributeValue, items int, offset int64) error {
+	c.Items = items
+	c.Offset = offset
+
+	if lastEvaluatedKey == nil {
+		c.Segments[segment] = &SegmentCheckpoint{Done: true}
+		return nil
+	}
+
+	converted, err := ddb.ConvertAVValue(lastEvaluatedKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(converted)
+	if err != nil {
+		return err
+	}
+
+	var key map[string]any
+	if err = json.Unmarshal(data, &key); err != nil {
+		return err
+	}
+
+	c.Segments[segment] = &SegmentCheckpoint{LastEvaluatedKey: key}
+	return nil
+}
+
+// startKey - returns the key a segment should resume from and, whether the segment is already done
+func (c *Checkpoint) startKey(segment int32) (map[string]types.AttributeValue, bool, error) {
+	if c == nil {
+		return nil, false, nil
+	}
+
+	progress, ok := c.Segments[segment]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if progress.Done {
+		return nil, true, nil
+	}
+
+	if progress.LastEvaluatedKey == nil {
+		return nil, false, nil
+	}
+
+	key, err := ddb.ParseAVValue(progress.LastEvaluatedKey)
+	return key, false, err
+}
+
+// FileCheckpoint - stores a checkpoint as a json file
+type FileCheckpoint struct {
+	path string
+}
+
+// NewFileCheckpoint - creates a checkpoint store at the given path
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{
+		path: path,
+	}
+}
+
+// Load - loads the checkpoint, returns ErrNoCheckpoint if the file does not exist
+func (f *FileCheckpoint) Load() (*Checkpoint, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoCheckpoint, f.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint Checkpoint
+	if err = json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
+
+// Save - saves the checkpoint, replacing the file so a partially written checkpoint is never read
+func (f *FileCheckpoint) Save(checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// loadCheckpoint - loads the checkpoint to resume from, or creates a new checkpoint when not resuming.
+// Returns nil when no checkpoint store is configured.
+func (s Service) loadCheckpoint(tableName string, queryOpts *QueryOpts) (*Checkpoint, error) {
+	if queryOpts.Checkpoint == nil {
+		if queryOpts.Resume {
+			return nil, fmt.Errorf("%w: resume requires a checkpoint", ErrNoCheckpoint)
+		}
+		return nil, nil
+	}
+
+	expected, err := newCheckpoint(tableName, queryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !queryOpts.Resume {
+		return expected, nil
+	}
+
+	checkpoint, err := queryOpts.Checkpoint.Load()
+	if err != nil {
+		s.logger.Error("could not load checkpoint", "error", err)
+		return nil, err
+	}
+
+	if err = checkpoint.validate(expected); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("resuming from checkpoint", "items", checkpoint.Items, "offset", checkpoint.Offset)
+	return checkpoint, nil
+}
+
+// saveCheckpoint - records the progress of a segment and saves the checkpoint.
+// Callers must serialise calls to saveCheckpoint.
+func (s Service) saveCheckpoint(store CheckpointStore, checkpoint *Checkpoint, segment int32, lastEvaluatedKey map[string]types.AttributeValue, items int, offset int64) error {
+	if checkpoint == nil || s.dryRun {
+		return nil
+	}
+
+	if err := checkpoint.update(segment, lastEvaluatedKey, items, offset); err != nil {
+		s.logger.Error("could not update checkpoint", "error", err)
+		return err
+	}
+
+	if err := store.Save(checkpoint); err != nil {
+		s.logger.Error("could not save checkpoint", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// offsetWriter - tracks the number of bytes written, so a resumed dump can continue from the last checkpoint
+type offsetWriter struct {
+	writer Writer
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.offset += int64(n)
+	return n, err
+}
+
+func (w *offsetWriter) WriteString(str string) (int, error) {
+	n, err := w.writer.WriteString(str)
+	w.offset += int64(n)
+	return n, err
+}
@@ -0,0 +1,121 @@
+package goety
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/odize"
+)
+
+type mockCheckpointStore struct {
+	checkpoint *Checkpoint
+	saves      int
+}
+
+func (m *mockCheckpointStore) Load() (*Checkpoint, error) {
+	if m.checkpoint == nil {
+		return nil, ErrNoCheckpoint
+	}
+
+	return m.checkpoint, nil
+}
+
+func (m *mockCheckpointStore) Save(checkpoint *Checkpoint) error {
+	m.saves++
+	m.checkpoint = checkpoint
+	return nil
+}
+
+func TestFileCheckpoint(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var store *FileCheckpoint
+
+	group.BeforeEach(func() {
+		store = NewFileCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"))
+	})
+
+	err := group.
+		Test("should return no checkpoint if file does not exist", func(t *testing.T) {
+			_, err := store.Load()
+			odize.AssertTrue(t, errors.Is(err, ErrNoCheckpoint))
+		}).
+		Test("should round trip segment keys", func(t *testing.T) {
+			checkpoint, err := newCheckpoint("my-table", &QueryOpts{Segments: 2})
+			odize.AssertNoError(t, err)
+			lastKey := map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: "pk"},
+				"sk": &types.AttributeValueMemberN{Value: "10"},
+			}
+
+			odize.AssertNoError(t, checkpoint.update(0, lastKey, 5, 100))
+			odize.AssertNoError(t, checkpoint.update(1, nil, 6, 120))
+			odize.AssertNoError(t, store.Save(checkpoint))
+
+			loaded, err := store.Load()
+			odize.AssertNoError(t, err)
+			odize.AssertNoError(t, loaded.validate(checkpoint))
+
+			odize.AssertEqual(t, 6, loaded.Items)
+			odize.AssertEqual(t, int64(120), loaded.Offset)
+
+			key, done, err := loaded.startKey(0)
+			odize.AssertNoError(t, err)
+			odize.AssertFalse(t, done)
+			odize.AssertEqual(t, lastKey, key)
+
+			_, done, err = loaded.startKey(1)
+			odize.AssertNoError(t, err)
+			odize.AssertTrue(t, done)
+		}).
+		Test("should not validate a checkpoint for another table", func(t *testing.T) {
+			err := newTestCheckpoint(t, "my-table").validate(newTestCheckpoint(t, "other-table"))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointMismatch))
+		}).
+		Test("should not validate a checkpoint with different segments", func(t *testing.T) {
+			err := newTestCheckpoint(t, "my-table", WithSegments(4)).validate(newTestCheckpoint(t, "my-table", WithSegments(2)))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointMismatch))
+		}).
+		Test("should not validate a checkpoint with a different format", func(t *testing.T) {
+			err := newTestCheckpoint(t, "my-table", WithFormat(FormatJSONL)).validate(newTestCheckpoint(t, "my-table", WithFormat(FormatJSON)))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointMismatch))
+		}).
+		Test("should not validate a checkpoint with a different filter", func(t *testing.T) {
+			checkpoint := newTestCheckpoint(t, "my-table", WithFilterExpression("#age > :age"), WithFilterNameAttrs("#age=age"), WithFilterNameValues(":age=N:30"))
+
+			odize.AssertNoError(t, checkpoint.validate(newTestCheckpoint(t, "my-table", WithFilterExpression("#age > :age"), WithFilterNameAttrs("#age=age"), WithFilterNameValues(":age=N:30"))))
+
+			err := checkpoint.validate(newTestCheckpoint(t, "my-table", WithFilterExpression("#age > :age"), WithFilterNameAttrs("#age=age"), WithFilterNameValues(":age=N:40")))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointMismatch))
+
+			err = checkpoint.validate(newTestCheckpoint(t, "my-table"))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointMismatch))
+		}).
+		Test("should not validate a checkpoint with a different partition", func(t *testing.T) {
+			sort, err := ParseSortCondition("begins_with order#")
+			odize.AssertNoError(t, err)
+			other, err := ParseSortCondition("begins_with invoice#")
+			odize.AssertNoError(t, err)
+
+			checkpoint := newTestCheckpoint(t, "my-table", WithPartition("tenant-1"), WithSortCondition(sort))
+			odize.AssertNoError(t, checkpoint.validate(newTestCheckpoint(t, "my-table", WithPartition("tenant-1"), WithSortCondition(sort))))
+
+			err = checkpoint.validate(newTestCheckpoint(t, "my-table", WithPartition("tenant-2"), WithSortCondition(sort)))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointMismatch))
+
+			err = checkpoint.validate(newTestCheckpoint(t, "my-table", WithPartition("tenant-1"), WithSortCondition(other)))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointMismatch))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+// newTestCheckpoint - creates a checkpoint for the table and query options
+func newTestCheckpoint(t *testing.T, tableName string, opts ...QueryFuncOpts) *Checkpoint {
+	checkpoint, err := newCheckpoint(tableName, WithQueryOptions(opts))
+	odize.AssertNoError(t, err)
+	return checkpoint
+}
@@ -0,0 +1,137 @@
+package goety
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+)
+
+// Compression - compression applied to dump output and seed input
+type Compression string
+
+const (
+	// CompressionNone - no compression
+	CompressionNone Compression = "none"
+	// CompressionGzip - gzip compression
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd - zstandard compression
+	CompressionZstd Compression = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ParseCompression - parses the given compression, returning ErrUnsupportedCompression if it is not supported
+func ParseCompression(compression string) (Compression, error) {
+	switch Compression(compression) {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		return Compression(compression), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+}
+
+// CompressionFromPath - returns the compression implied by the file extension, no compression if the extension is not recognised
+func CompressionFromPath(path string) Compression {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return CompressionGzip
+	case ".zst", ".zstd":
+		return CompressionZstd
+	}
+
+	return CompressionNone
+}
+
+// trimCompressionExt - removes a compression extension from the path, so the file format can be read from the remaining extension
+func trimCompressionExt(path string) string {
+	if CompressionFromPath(path) == CompressionNone {
+		return path
+	}
+
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// CompressedWriter - a Writer that compresses to the underlying writer, Close must be called to flush the compressed stream
+type CompressedWriter struct {
+	writer io.WriteCloser
+}
+
+// NewCompressedWriter - wraps the writer with the given compression.
+// Closing the compressed writer does not close the underlying writer.
+//
+// Example:
+//
+//	writer, err := NewCompressedWriter(file, CompressionGzip)
+//	defer writer.Close()
+func NewCompressedWriter(writer io.Writer, compression Compression) (*CompressedWriter, error) {
+	switch compression {
+	case CompressionGzip:
+		return &CompressedWriter{writer: gzip.NewWriter(writer)}, nil
+	case CompressionZstd:
+		encoder, err := zstd.NewWriter(writer)
+		if err != nil {
+			return nil, err
+		}
+		return &CompressedWriter{writer: encoder}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+}
+
+func (w *CompressedWriter) Write(p []byte) (int, error) {
+	return w.writer.Write(p)
+}
+
+func (w *CompressedWriter) WriteString(s string) (int, error) {
+	return w.writer.Write([]byte(s))
+}
+
+// Close - flushes any buffered data and writes the end of the compressed stream
+func (w *CompressedWriter) Close() error {
+	return w.writer.Close()
+}
+
+// decompress - detects compressed input from the leading magic bytes and, returns a reader of the decompressed input.
+// Input that is not compressed is returned as is. The returned close function releases the decompressor.
+func decompress(reader *bufio.Reader) (*bufio.Reader, func() error, error) {
+	noop := func() error { return nil }
+
+	magic, err := reader.Peek(len(zstdMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, noop, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, noop, err
+		}
+		return bufio.NewReader(gz), gz.Close, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, noop, err
+		}
+		return bufio.NewReader(decoder), func() error {
+			decoder.Close()
+			return nil
+		}, nil
+	}
+
+	return reader, noop, nil
+}
@@ -0,0 +1,65 @@
+package goety
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestNewCompressedWriter(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	roundTrip := func(t *testing.T, compression Compression) string {
+		buf := bytes.Buffer{}
+
+		writer, err := NewCompressedWriter(&buf, compression)
+		odize.AssertNoError(t, err)
+
+		_, err = writer.WriteString("[\n")
+		odize.AssertNoError(t, err)
+		_, err = writer.Write([]byte("{}\n]"))
+		odize.AssertNoError(t, err)
+		odize.AssertNoError(t, writer.Close())
+
+		reader, closeReader, err := decompress(bufio.NewReader(&buf))
+		odize.AssertNoError(t, err)
+		defer closeReader()
+
+		data, err := io.ReadAll(reader)
+		odize.AssertNoError(t, err)
+		return string(data)
+	}
+
+	err := group.
+		Test("should round trip gzip", func(t *testing.T) {
+			odize.AssertEqual(t, "[\n{}\n]", roundTrip(t, CompressionGzip))
+		}).
+		Test("should round trip zstd", func(t *testing.T) {
+			odize.AssertEqual(t, "[\n{}\n]", roundTrip(t, CompressionZstd))
+		}).
+		Test("should return error on unsupported compression", func(t *testing.T) {
+			_, err := NewCompressedWriter(&bytes.Buffer{}, CompressionNone)
+			odize.AssertTrue(t, errors.Is(err, ErrUnsupportedCompression))
+		}).
+		Test("should not decompress plain input", func(t *testing.T) {
+			reader, _, err := decompress(bufio.NewReader(bytes.NewBufferString("[]")))
+			odize.AssertNoError(t, err)
+
+			data, err := io.ReadAll(reader)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "[]", string(data))
+		}).
+		Test("should return compression from file extension", func(t *testing.T) {
+			odize.AssertEqual(t, CompressionGzip, CompressionFromPath("dump.json.gz"))
+			odize.AssertEqual(t, CompressionZstd, CompressionFromPath("dump.jsonl.zst"))
+			odize.AssertEqual(t, CompressionNone, CompressionFromPath("dump.json"))
+			odize.AssertEqual(t, FormatJSONL, FormatFromPath("dump.jsonl.gz"))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
@@ -0,0 +1,515 @@
+package goety
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrUnsupportedCSVNested = errors.New("unsupported csv nested mode")
+	ErrUnsupportedCSVType   = errors.New("unsupported csv column type")
+	ErrInvalidCSVValue      = errors.New("invalid csv value")
+)
+
+// dynamodbNumber - the text of a number dynamodb accepts, an optional sign, decimal digits with an optional point and, an optional exponent
+var dynamodbNumber = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// csvTypes - attribute types a csv column can be hinted as, columns without a hint are strings
+var csvTypes = []string{"S", "N", "B", "BOOL", "NULL", "SS", "NS", "BS", "M", "L"}
+
+// CSVNested - how nested map and list values are written to csv
+type CSVNested string
+
+const (
+	// CSVNestedJSON - nested values are written as json in a single cell
+	CSVNestedJSON CSVNested = "json"
+	// CSVNestedDotted - nested values are written to a column per leaf value, named by the dotted path e.g. "address.city" or "tags.0"
+	CSVNestedDotted CSVNested = "dotted"
+)
+
+// ParseCSVNested - parses the given nested mode, returning ErrUnsupportedCSVNested if it is not supported
+func ParseCSVNested(nested string) (CSVNested, error) {
+	switch CSVNested(nested) {
+	case CSVNestedJSON, CSVNestedDotted:
+		return CSVNested(nested), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedCSVNested, nested)
+}
+
+// csvItemWriter - writes items as csv rows.
+// When columns are provided, rows are streamed to the writer. Otherwise, the header is the union of attribute names,
+// so items are spooled to a temporary file and, written once every attribute name is known.
+type csvItemWriter struct {
+	writer  *csv.Writer
+	columns []string
+	nested  CSVNested
+	spool   itemSpool
+	names   map[string]struct{}
+}
+
+// newCSVItemWriter - creates a csv item writer, columns may be empty to derive the header from the items
+func newCSVItemWriter(writer Writer, columns []string, nested CSVNested) *csvItemWriter {
+	if nested == "" {
+		nested = CSVNestedJSON
+	}
+
+	return &csvItemWriter{
+		writer:  csv.NewWriter(writer),
+		columns: columns,
+		nested:  nested,
+		names:   map[string]struct{}{},
+	}
+}
+
+func (w *csvItemWriter) begin() error {
+	if len(w.columns) == 0 {
+		return nil
+	}
+
+	return w.writeRow(w.columns)
+}
+
+func (w *csvItemWriter) write(item map[string]any, _ int) error {
+	if len(w.columns) > 0 {
+		return w.writeRow(csvRow(item, w.columns))
+	}
+
+	for _, name := range csvColumnNames(item, w.nested) {
+		w.names[name] = struct{}{}
+	}
+
+	return w.spool.add(item)
+}
+
+func (w *csvItemWriter) end() error {
+	if len(w.columns) > 0 {
+		return nil
+	}
+
+	defer w.spool.close()
+
+	columns := make([]string, 0, len(w.names))
+	for name := range w.names {
+		columns = append(columns, name)
+	}
+	slices.Sort(columns)
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	if err := w.writeRow(columns); err != nil {
+		return err
+	}
+
+	return w.spool.each(func(item map[string]any) error {
+		return w.writeRow(csvRow(item, columns))
+	})
+}
+
+// writeRow - writes and flushes a row, so the bytes written are known when a checkpoint is saved
+func (w *csvItemWriter) writeRow(row []string) error {
+	if err := w.writer.Write(row); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// csvColumnNames - returns the column names of an item, nested values are expanded to dotted paths in dotted mode
+func csvColumnNames(item map[string]any, nested CSVNested) []string {
+	names := []string{}
+	for key, value := range item {
+		if nested == CSVNestedDotted {
+			names = append(names, dottedPaths(key, value)...)
+			continue
+		}
+		names = append(names, key)
+	}
+
+	return names
+}
+
+// dottedPaths - returns the dotted path to each leaf value, empty maps and lists are leaf values
+func dottedPaths(prefix string, value any) []string {
+	paths := []string{}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return []string{prefix}
+		}
+		for key, nestedValue := range v {
+			paths = append(paths, dottedPaths(prefix+"."+key, nestedValue)...)
+		}
+	case []any:
+		if len(v) == 0 {
+			return []string{prefix}
+		}
+		for i, nestedValue := range v {
+			paths = append(paths, dottedPaths(prefix+"."+strconv.Itoa(i), nestedValue)...)
+		}
+	default:
+		return []string{prefix}
+	}
+
+	return paths
+}
+
+// csvRow - returns the cells of an item for each column
+func csvRow(item map[string]any, columns []string) []string {
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		value, ok := lookupPath(item, column)
+		if !ok {
+			continue
+		}
+		row[i] = csvCell(value)
+	}
+
+	return row
+}
+
+// lookupPath - looks up a column in an item, an attribute named by the column is preferred over a dotted path
+func lookupPath(item map[string]any, column string) (any, bool) {
+	if value, ok := item[column]; ok {
+		return value, true
+	}
+
+	var current any = item
+	for _, part := range strings.Split(column, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[part]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// csvCell - formats a value as a csv cell, nested values and sets are written as json
+func csvCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// ParseColumnTypes - parses column type hints in the form "name:TYPE", e.g. "price:N" or "tags:SS"
+func ParseColumnTypes(hints []string) (map[string]string, error) {
+	columnTypes := map[string]string{}
+
+	for _, hint := range hints {
+		name, attrType, ok := splitTypeHint(hint)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCSVType, hint)
+		}
+		columnTypes[name] = attrType
+	}
+
+	return columnTypes, nil
+}
+
+// splitTypeHint - splits a "name:TYPE" hint, returns false when the suffix is not a supported type
+func splitTypeHint(hint string) (string, string, bool) {
+	index := strings.LastIndex(hint, ":")
+	if index <= 0 {
+		return hint, "", false
+	}
+
+	attrType := strings.ToUpper(hint[index+1:])
+	if !slices.Contains(csvTypes, attrType) {
+		return hint, "", false
+	}
+
+	return hint[:index], attrType, true
+}
+
+// csvColumn - a column of a seeded csv file
+type csvColumn struct {
+	name     string
+	attrType string
+}
+
+// csvItemReader - reads items from csv rows, the first row is a header of attribute names with optional type hints
+type csvItemReader struct {
+	reader  *csv.Reader
+	columns []csvColumn
+	row     int
+}
+
+// newCSVItemReader - reads the header row, column types override the type hints in the header
+func newCSVItemReader(reader io.Reader, columnTypes map[string]string) (*csvItemReader, error) {
+	csvReader := csv.NewReader(reader)
+
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return &csvItemReader{reader: csvReader}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]csvColumn, len(header))
+	for i, cell := range header {
+		if i == 0 {
+			cell = strings.TrimPrefix(cell, "\ufeff")
+		}
+
+		name, attrType, ok := splitTypeHint(cell)
+		if !ok {
+			attrType = "S"
+		}
+
+		if override, ok := columnTypes[name]; ok {
+			attrType = override
+		}
+
+		columns[i] = csvColumn{name: name, attrType: attrType}
+	}
+
+	return &csvItemReader{
+		reader:  csvReader,
+		columns: columns,
+		row:     1,
+	}, nil
+}
+
+func (r *csvItemReader) read() (map[string]types.AttributeValue, error) {
+	if r.columns == nil {
+		return nil, io.EOF
+	}
+
+	record, err := r.reader.Read()
+	if errors.Is(err, csv.ErrFieldCount) {
+		r.row++
+		return nil, &seedItemError{item: record, err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.row++
+
+	item := map[string]types.AttributeValue{}
+	for i, column := range r.columns {
+		if record[i] == "" && column.attrType != "NULL" {
+			continue
+		}
+
+		value, err := csvAttributeValue(record[i], column.attrType)
+		if err != nil {
+			return nil, &seedItemError{
+				item: csvRecordItem(r.columns, record),
+				err:  fmt.Errorf("%w: row %d, column %s: %v", ErrInvalidCSVValue, r.row, column.name, err),
+			}
+		}
+		item[column.name] = value
+	}
+
+	return item, nil
+}
+
+// csvRecordItem - returns the cells of a row by column name
+func csvRecordItem(columns []csvColumn, record []string) map[string]string {
+	item := map[string]string{}
+	for i, column := range columns {
+		item[column.name] = record[i]
+	}
+
+	return item
+}
+
+// csvAttributeValue - converts a csv cell to the attribute type.
+// Sets are a json array or comma separated values, maps and lists are json.
+func csvAttributeValue(cell string, attrType string) (types.AttributeValue, error) {
+	switch attrType {
+	case "S":
+		return &types.AttributeValueMemberS{Value: cell}, nil
+	case "N":
+		return csvNumber(cell)
+	case "B":
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(cell))
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberB{Value: data}, nil
+	case "BOOL":
+		value, err := strconv.ParseBool(strings.TrimSpace(cell))
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberBOOL{Value: value}, nil
+	case "NULL":
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case "SS":
+		values, err := csvSet(cell)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberSS{Value: values}, nil
+	case "NS":
+		values, err := csvSet(cell)
+		if err != nil {
+			return nil, err
+		}
+		normalised := make([]string, 0, len(values))
+		for _, value := range values {
+			if _, err = csvNumber(value); err != nil {
+				return nil, err
+			}
+			if slices.Contains(normalised, normaliseNumber(value)) {
+				return nil, fmt.Errorf("duplicate set member %q", value)
+			}
+			normalised = append(normalised, normaliseNumber(value))
+		}
+		return &types.AttributeValueMemberNS{Value: values}, nil
+	case "BS":
+		values, err := csvSet(cell)
+		if err != nil {
+			return nil, err
+		}
+		data := make([][]byte, len(values))
+		for i, value := range values {
+			if data[i], err = base64.StdEncoding.DecodeString(value); err != nil {
+				return nil, err
+			}
+		}
+		return &types.AttributeValueMemberBS{Value: data}, nil
+	case "M", "L":
+		return csvDocument(cell, attrType)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCSVType, attrType)
+}
+
+// csvNumber - validates the numeric text of a cell, the text is kept as is so no precision is lost
+func csvNumber(cell string) (types.AttributeValue, error) {
+	value := strings.TrimSpace(cell)
+	if !dynamodbNumber.MatchString(value) {
+		return nil, fmt.Errorf("not a number %q", cell)
+	}
+
+	return &types.AttributeValueMemberN{Value: value}, nil
+}
+
+// normaliseNumber - returns the exact value of a number as a fraction, so numbers written differently e.g. "1" and "1.0" are equal.
+// Text that is not a number is returned as is.
+func normaliseNumber(value string) string {
+	number, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return value
+	}
+
+	return number.RatString()
+}
+
+// csvSet - splits a set cell, written as a json array or comma separated values.
+// Sets cannot be empty or hold duplicate members.
+func csvSet(cell string) ([]string, error) {
+	value := strings.TrimSpace(cell)
+
+	var values []string
+	if strings.HasPrefix(value, "[") {
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.UseNumber()
+
+		var elements []any
+		if err := decoder.Decode(&elements); err != nil {
+			return nil, err
+		}
+
+		values = make([]string, len(elements))
+		for i, element := range elements {
+			values[i] = fmt.Sprint(element)
+		}
+	} else {
+		values = strings.Split(value, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("empty set")
+	}
+
+	for i, member := range values {
+		if slices.Contains(values[:i], member) {
+			return nil, fmt.Errorf("duplicate set member %q", member)
+		}
+	}
+
+	return values, nil
+}
+
+// csvDocument - unmarshals a json cell and marshals it to a map or list attribute
+func csvDocument(cell string, attrType string) (types.AttributeValue, error) {
+	decoder := json.NewDecoder(strings.NewReader(cell))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	value, err := attributevalue.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value.(type) {
+	case *types.AttributeValueMemberM:
+		if attrType == "M" {
+			return value, nil
+		}
+	case *types.AttributeValueMemberL:
+		if attrType == "L" {
+			return value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("not a %s value %q", attrType, cell)
+}
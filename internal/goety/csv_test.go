@@ -0,0 +1,169 @@
+package goety
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestCSVItemWriter(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	items := []map[string]any{
+		{
+			"pk":      "pk-0",
+			"count":   json.Number("12345678901234567890"),
+			"active":  true,
+			"address": map[string]any{"city": "Brisbane", "postcode": "4000"},
+		},
+		{
+			"pk":   "pk-1",
+			"tags": []any{"a", "b"},
+		},
+	}
+
+	writeAll := func(t *testing.T, writer *csvItemWriter) {
+		odize.AssertNoError(t, writer.begin())
+		for i, item := range items {
+			odize.AssertNoError(t, writer.write(item, i))
+		}
+		odize.AssertNoError(t, writer.end())
+	}
+
+	err := group.
+		Test("should derive header from the union of attribute names", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writeAll(t, newCSVItemWriter(&buf, nil, CSVNestedJSON))
+
+			expected := "active,address,count,pk,tags\n" +
+				"true,\"{\"\"city\"\":\"\"Brisbane\"\",\"\"postcode\"\":\"\"4000\"\"}\",12345678901234567890,pk-0,\n" +
+				",,,pk-1,\"[\"\"a\"\",\"\"b\"\"]\"\n"
+			odize.AssertEqual(t, expected, buf.String())
+		}).
+		Test("should write nested values as dotted columns", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writeAll(t, newCSVItemWriter(&buf, nil, CSVNestedDotted))
+
+			expected := "active,address.city,address.postcode,count,pk,tags.0,tags.1\n" +
+				"true,Brisbane,4000,12345678901234567890,pk-0,,\n" +
+				",,,,pk-1,a,b\n"
+			odize.AssertEqual(t, expected, buf.String())
+		}).
+		Test("should stream rows for the given columns", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writer := newCSVItemWriter(&buf, []string{"pk", "address.city"}, CSVNestedJSON)
+
+			odize.AssertNoError(t, writer.begin())
+			odize.AssertNoError(t, writer.write(items[0], 0))
+			odize.AssertEqual(t, "pk,address.city\npk-0,Brisbane\n", buf.String())
+		}).
+		Test("should write nothing without items", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writer := newCSVItemWriter(&buf, nil, CSVNestedJSON)
+
+			odize.AssertNoError(t, writer.begin())
+			odize.AssertNoError(t, writer.end())
+			odize.AssertEqual(t, "", buf.String())
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func TestCSVItemReader(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should convert cells with type hints", func(t *testing.T) {
+			file := "\ufeffpk,price:N,active:bool,tags:SS,ids:NS,address:M,notes\n" +
+				"pk-0,12345678901234567890,true,\"[\"\"a\"\",\"\"b\"\"]\",\"1, 2\",\"{\"\"city\"\":\"\"Brisbane\"\"}\",\n"
+
+			reader, err := newCSVItemReader(strings.NewReader(file), nil)
+			odize.AssertNoError(t, err)
+
+			item, err := reader.read()
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, "pk-0", item["pk"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, "12345678901234567890", item["price"].(*types.AttributeValueMemberN).Value)
+			odize.AssertEqual(t, true, item["active"].(*types.AttributeValueMemberBOOL).Value)
+			odize.AssertEqual(t, []string{"a", "b"}, item["tags"].(*types.AttributeValueMemberSS).Value)
+			odize.AssertEqual(t, []string{"1", "2"}, item["ids"].(*types.AttributeValueMemberNS).Value)
+
+			address := item["address"].(*types.AttributeValueMemberM).Value
+			odize.AssertEqual(t, "Brisbane", address["city"].(*types.AttributeValueMemberS).Value)
+
+			_, ok := item["notes"]
+			odize.AssertFalse(t, ok)
+
+			_, err = reader.read()
+			odize.AssertTrue(t, errors.Is(err, io.EOF))
+		}).
+		Test("should override header type hints with column types", func(t *testing.T) {
+			reader, err := newCSVItemReader(strings.NewReader("pk,count:S\npk-0,10\n"), map[string]string{"count": "N"})
+			odize.AssertNoError(t, err)
+
+			item, err := reader.read()
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "10", item["count"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should return row number on conversion error", func(t *testing.T) {
+			reader, err := newCSVItemReader(strings.NewReader("pk,price:N\npk-0,10\npk-1,ten\n"), nil)
+			odize.AssertNoError(t, err)
+
+			_, err = reader.read()
+			odize.AssertNoError(t, err)
+
+			_, err = reader.read()
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCSVValue))
+			odize.AssertTrue(t, strings.Contains(err.Error(), "row 3, column price"))
+		}).
+		Test("should return error for numbers dynamodb rejects", func(t *testing.T) {
+			for _, number := range []string{"Inf", "NaN", "0x10", "1_000", "1e", ""} {
+				_, err := csvNumber(number)
+				odize.AssertError(t, err)
+			}
+
+			for _, number := range []string{"10", "-1.5", ".5", "1.", "+2", "1.5E-3"} {
+				_, err := csvNumber(number)
+				odize.AssertNoError(t, err)
+			}
+		}).
+		Test("should return row number for an empty set", func(t *testing.T) {
+			reader, err := newCSVItemReader(strings.NewReader("pk,tags:SS\npk-0,[]\n"), nil)
+			odize.AssertNoError(t, err)
+
+			_, err = reader.read()
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCSVValue))
+			odize.AssertTrue(t, strings.Contains(err.Error(), "row 2, column tags: empty set"))
+		}).
+		Test("should return row number for duplicate set members", func(t *testing.T) {
+			reader, err := newCSVItemReader(strings.NewReader("pk,tags:SS,ids:NS\npk-0,\"a,a\",1\npk-1,a,\"1,1.0\"\n"), nil)
+			odize.AssertNoError(t, err)
+
+			_, err = reader.read()
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCSVValue))
+			odize.AssertTrue(t, strings.Contains(err.Error(), "row 2, column tags: duplicate set member"))
+
+			_, err = reader.read()
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidCSVValue))
+			odize.AssertTrue(t, strings.Contains(err.Error(), "row 3, column ids: duplicate set member"))
+		}).
+		Test("should parse column types", func(t *testing.T) {
+			columnTypes, err := ParseColumnTypes([]string{"price:N", "tags:ss"})
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, map[string]string{"price": "N", "tags": "SS"}, columnTypes)
+
+			_, err = ParseColumnTypes([]string{"price:DATE"})
+			odize.AssertTrue(t, errors.Is(err, ErrUnsupportedCSVType))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
@@ -0,0 +1,128 @@
+package goety
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+)
+
+var (
+	ErrSeedItemsFailed = errors.New("seed items failed")
+)
+
+// DeadLetter - an item that could not be seeded, with the error it failed with.
+// The index is the position of the item in the seed input, starting from 1.
+// Items that were read are dynamodb json, items that could not be read are as they appear in the input.
+type DeadLetter struct {
+	Index int    `json:"index"`
+	Item  any    `json:"item"`
+	Error string `json:"error"`
+}
+
+// SeedError - returned when seeding continued on error and, some items could not be seeded
+type SeedError struct {
+	Succeeded int
+	Failed    int
+}
+
+func (e *SeedError) Error() string {
+	return fmt.Sprintf("%s: %d of %d items failed", ErrSeedItemsFailed, e.Failed, e.Succeeded+e.Failed)
+}
+
+func (e *SeedError) Unwrap() error {
+	return ErrSeedItemsFailed
+}
+
+// seedItemError - an item was read from the input but could not be converted, reading can continue with the next item
+type seedItemError struct {
+	item any
+	err  error
+}
+
+func (e *seedItemError) Error() string {
+	return e.err.Error()
+}
+
+func (e *seedItemError) Unwrap() error {
+	return e.err
+}
+
+// FileDeadLetter - stores dead letters as a json array file, the file is created with the first dead letter
+type FileDeadLetter struct {
+	mx     sync.Mutex
+	path   string
+	file   *os.File
+	encode *json.Encoder
+}
+
+// NewFileDeadLetter - creates a dead letter store at the given path
+func NewFileDeadLetter(path string) *FileDeadLetter {
+	return &FileDeadLetter{
+		path: path,
+	}
+}
+
+// Add - appends the dead letter to the file
+func (f *FileDeadLetter) Add(letter DeadLetter) error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	separator := ",\n"
+	if f.file == nil {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return err
+		}
+		f.file = file
+		f.encode = json.NewEncoder(file)
+		separator = "[\n"
+	}
+
+	if _, err := f.file.WriteString(separator); err != nil {
+		return err
+	}
+
+	return f.encode.Encode(letter)
+}
+
+// Close - ends the json array and closes the file, nothing is written when there were no dead letters
+func (f *FileDeadLetter) Close() error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	if _, err := f.file.WriteString("]\n"); err != nil {
+		_ = f.file.Close()
+		return err
+	}
+
+	return f.file.Close()
+}
+
+// deadLetterItem - converts an item to dynamodb json for a dead letter
+func deadLetterItem(item map[string]types.AttributeValue) any {
+	converted, err := ddb.ConvertAVValue(item)
+	if err != nil {
+		return nil
+	}
+
+	data, err := json.Marshal(converted)
+	if err != nil {
+		return nil
+	}
+
+	var out map[string]any
+	if err = json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+
+	return out
+}
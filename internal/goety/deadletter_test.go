@@ -0,0 +1,56 @@
+package goety
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-gorilla-au/odize"
+)
+
+type mockDeadLetterStore struct {
+	letters []DeadLetter
+}
+
+func (m *mockDeadLetterStore) Add(letter DeadLetter) error {
+	m.letters = append(m.letters, letter)
+	return nil
+}
+
+func TestFileDeadLetter(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var path string
+	var store *FileDeadLetter
+
+	group.BeforeEach(func() {
+		path = filepath.Join(t.TempDir(), "dead-letter.json")
+		store = NewFileDeadLetter(path)
+	})
+
+	err := group.
+		Test("should write a json array of dead letters", func(t *testing.T) {
+			odize.AssertNoError(t, store.Add(DeadLetter{Index: 1, Item: map[string]any{"pk": "pk-1"}, Error: "first"}))
+			odize.AssertNoError(t, store.Add(DeadLetter{Index: 3, Item: map[string]any{"pk": "pk-3"}, Error: "second"}))
+			odize.AssertNoError(t, store.Close())
+
+			data, err := os.ReadFile(path)
+			odize.AssertNoError(t, err)
+
+			var letters []DeadLetter
+			odize.AssertNoError(t, json.Unmarshal(data, &letters))
+			odize.AssertEqual(t, 2, len(letters))
+			odize.AssertEqual(t, 3, letters[1].Index)
+			odize.AssertEqual(t, "second", letters[1].Error)
+		}).
+		Test("should not create a file without dead letters", func(t *testing.T) {
+			odize.AssertNoError(t, store.Close())
+
+			_, err := os.Stat(path)
+			odize.AssertTrue(t, os.IsNotExist(err))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
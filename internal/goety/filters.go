@@ -0,0 +1,145 @@
+package goety
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+)
+
+var (
+	ErrInvalidFilterValue = errors.New("invalid filter value")
+)
+
+// ParseFilterValues - parses expression attribute values in the form ":name=TYPE:value", separated by commas.
+// The type is optional and defaults to a string, supported types are S, N, B, BOOL, NULL, SS, NS, BS, M and L.
+// Commas within a value are escaped with a backslash, sets are a json array, maps and lists are json, commas within json need no escape.
+//
+// Example:
+//
+//	ParseFilterValues(":tenant=tenant-1,:age=N:30,:active=BOOL:true,:note=S:hello\, world,:tags=SS:[\"a\",\"b\"]")
+func ParseFilterValues(values string) (map[string]types.AttributeValue, error) {
+	parsed := map[string]types.AttributeValue{}
+	if strings.TrimSpace(values) == "" {
+		return parsed, nil
+	}
+
+	for _, entry := range splitEscaped(values, ',') {
+		name, av, err := parseFilterEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed[name] = av
+	}
+
+	return parsed, nil
+}
+
+// parseFilterEntry - parses a single ":name=TYPE:value" entry
+func parseFilterEntry(entry string) (string, types.AttributeValue, error) {
+	name, value, ok := strings.Cut(entry, "=")
+	name = strings.TrimSpace(name)
+	if !ok || !strings.HasPrefix(name, ":") || len(name) == 1 {
+		return "", nil, fmt.Errorf("%w: expected :name=value, got %q", ErrInvalidFilterValue, entry)
+	}
+
+	av, err := filterValue(strings.TrimSpace(value))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s: %v", ErrInvalidFilterValue, name, err)
+	}
+
+	return name, av, nil
+}
+
+// ParseFilterValuesJSON - parses expression attribute values from a dynamodb json object
+//
+// Example:
+//
+//	ParseFilterValuesJSON(`{":age": {"N": "30"}, ":tags": {"SS": ["a", "b"]}}`)
+func ParseFilterValuesJSON(values string) (map[string]types.AttributeValue, error) {
+	if strings.TrimSpace(values) == "" {
+		return map[string]types.AttributeValue{}, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(values))
+	decoder.UseNumber()
+
+	var data map[string]any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilterValue, err)
+	}
+
+	for name := range data {
+		if !strings.HasPrefix(name, ":") || len(name) == 1 {
+			return nil, fmt.Errorf("%w: expected a name starting with \":\", got %q", ErrInvalidFilterValue, name)
+		}
+	}
+
+	parsed, err := ddb.ParseAVValue(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilterValue, err)
+	}
+
+	return parsed, nil
+}
+
+// filterValue - converts a value with an optional type prefix, values without a supported type prefix are strings.
+// A value that is a string starting with a type prefix is written with an explicit string type e.g. "S:N:30".
+func filterValue(value string) (types.AttributeValue, error) {
+	attrType, typed, ok := strings.Cut(value, ":")
+	if !ok || !slices.Contains(csvTypes, attrType) {
+		return &types.AttributeValueMemberS{Value: value}, nil
+	}
+
+	return csvAttributeValue(typed, attrType)
+}
+
+// splitEscaped - splits the text on the separator, a backslash escapes the separator or another backslash.
+// Separators within json arrays and objects are not split on, so sets, maps and lists need no escapes.
+func splitEscaped(text string, separator rune) []string {
+	parts := []string{}
+	var current strings.Builder
+	escaped := false
+	depth := 0
+	quoted := false
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			// json strings keep their escapes, an escaped separator is unescaped anywhere
+			if r != separator && (r != '\\' || depth > 0) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == separator && depth == 0:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+			switch {
+			case quoted:
+				quoted = r != '"'
+			case depth > 0 && r == '"':
+				quoted = true
+			case r == '[' || r == '{':
+				depth++
+			case (r == ']' || r == '}') && depth > 0:
+				depth--
+			}
+		}
+	}
+
+	if escaped {
+		current.WriteRune('\\')
+	}
+
+	return append(parts, current.String())
+}
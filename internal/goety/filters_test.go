@@ -0,0 +1,130 @@
+package goety
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestParseFilterValues(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should parse untyped values as strings", func(t *testing.T) {
+			values, err := ParseFilterValues(":tenant=tenant-1, :time=2024-01-01T10:00")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, map[string]types.AttributeValue{
+				":tenant": &types.AttributeValueMemberS{Value: "tenant-1"},
+				":time":   &types.AttributeValueMemberS{Value: "2024-01-01T10:00"},
+			}, values)
+		}).
+		Test("should parse typed values", func(t *testing.T) {
+			values, err := ParseFilterValues(":age=N:30,:active=BOOL:true,:deleted=NULL:,:tags=SS:[\"a\"\\,\"b\"]")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, map[string]types.AttributeValue{
+				":age":     &types.AttributeValueMemberN{Value: "30"},
+				":active":  &types.AttributeValueMemberBOOL{Value: true},
+				":deleted": &types.AttributeValueMemberNULL{Value: true},
+				":tags":    &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			}, values)
+		}).
+		Test("should not split on commas within json values", func(t *testing.T) {
+			values, err := ParseFilterValues(`:tags=SS:["a","b"],:address=M:{"city":"Brisbane, QLD","codes":[1,2]},:age=N:30`)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, []string{"a", "b"}, values[":tags"].(*types.AttributeValueMemberSS).Value)
+
+			address := values[":address"].(*types.AttributeValueMemberM).Value
+			odize.AssertEqual(t, "Brisbane, QLD", address["city"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, 2, len(address["codes"].(*types.AttributeValueMemberL).Value))
+			odize.AssertEqual(t, "30", values[":age"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should keep escapes within json strings", func(t *testing.T) {
+			values, err := ParseFilterValues(`:tags=SS:["a\"b","c\\d"]`)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, []string{`a"b`, `c\d`}, values[":tags"].(*types.AttributeValueMemberSS).Value)
+		}).
+		Test("should keep escaped commas and equals signs in values", func(t *testing.T) {
+			values, err := ParseFilterValues(`:note=S:hello\, world,:query=a=b`)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "hello, world", values[":note"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, "a=b", values[":query"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should keep an explicit string type prefix", func(t *testing.T) {
+			values, err := ParseFilterValues(":code=S:N:30")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "N:30", values[":code"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should return error for a malformed number", func(t *testing.T) {
+			_, err := ParseFilterValues(":age=N:thirty")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidFilterValue))
+		}).
+		Test("should return error for a malformed bool", func(t *testing.T) {
+			_, err := ParseFilterValues(":active=BOOL:yes please")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidFilterValue))
+		}).
+		Test("should return error for a name without a colon", func(t *testing.T) {
+			_, err := ParseFilterValues("age=N:30")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidFilterValue))
+		}).
+		Test("should return error for an entry without a value", func(t *testing.T) {
+			_, err := ParseFilterValues(":age")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidFilterValue))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestParseFilterValuesJSON(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should parse dynamodb json", func(t *testing.T) {
+			values, err := ParseFilterValuesJSON(`{":age": {"N": "30"}, ":active": {"BOOL": true}, ":name": {"S": "a, b=c"}}`)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, map[string]types.AttributeValue{
+				":age":    &types.AttributeValueMemberN{Value: "30"},
+				":active": &types.AttributeValueMemberBOOL{Value: true},
+				":name":   &types.AttributeValueMemberS{Value: "a, b=c"},
+			}, values)
+		}).
+		Test("should return no values for empty input", func(t *testing.T) {
+			values, err := ParseFilterValuesJSON("")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, 0, len(values))
+		}).
+		Test("should return error for values that are not dynamodb json", func(t *testing.T) {
+			_, err := ParseFilterValuesJSON(`{":age": 30}`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidFilterValue))
+		}).
+		Test("should return error for invalid json", func(t *testing.T) {
+			_, err := ParseFilterValuesJSON(`{":age": `)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidFilterValue))
+		}).
+		Test("should return error for a name without a colon", func(t *testing.T) {
+			_, err := ParseFilterValuesJSON(`{"age": {"N": "30"}}`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidFilterValue))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestWithFilterNameValues(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should skip only malformed values", func(t *testing.T) {
+			opts := WithQueryOptions([]QueryFuncOpts{WithFilterNameValues(`:age=N:thirty,:tenant=tenant-1,:note=S:hello\, world`)})
+			odize.AssertEqual(t, map[string]types.AttributeValue{
+				":tenant": &types.AttributeValueMemberS{Value: "tenant-1"},
+				":note":   &types.AttributeValueMemberS{Value: "hello, world"},
+			}, opts.FilterNameValues)
+		}).
+		Test("should provide no values for empty input", func(t *testing.T) {
+			opts := WithQueryOptions([]QueryFuncOpts{WithFilterNameValues(" ")})
+			odize.AssertEqual(t, 0, len(opts.FilterNameValues))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
@@ -0,0 +1,314 @@
+package goety
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+	"github.com/code-gorilla-au/goety/internal/logging"
+)
+
+var (
+	ErrUnsupportedFormat     = errors.New("unsupported format")
+	ErrCheckpointUnsupported = errors.New("checkpoint is not supported for the output format")
+)
+
+// Format - file format items are dumped to or seeded from
+type Format string
+
+const (
+	// FormatJSON - a json array of items
+	FormatJSON Format = "json"
+	// FormatJSONL - newline delimited json, one item per line
+	FormatJSONL Format = "jsonl"
+	// FormatCSV - comma separated values with a header row
+	FormatCSV Format = "csv"
+	// FormatParquet - a parquet file with a schema inferred from the items
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat - parses the given format, returning ErrUnsupportedFormat if it is not supported
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case FormatJSON, FormatJSONL, FormatCSV, FormatParquet:
+		return Format(format), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// ParseSeedFormat - parses the given format, returning ErrUnsupportedFormat if items cannot be seeded from it.
+// Parquet is only supported as a dump output.
+func ParseSeedFormat(format string) (Format, error) {
+	parsed, err := ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	if parsed == FormatParquet {
+		return "", fmt.Errorf("%w: %s cannot be seeded, dump to json, jsonl or csv to seed", ErrUnsupportedFormat, format)
+	}
+
+	return parsed, nil
+}
+
+// FormatFromPath - returns the format implied by the file extension, an empty format if the extension is not recognised.
+// A compression extension is ignored, so "dump.jsonl.gz" is json lines.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(trimCompressionExt(path))) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	case ".parquet":
+		return FormatParquet
+	}
+
+	return ""
+}
+
+// detectFormat - detects the format from the first non whitespace byte, a json array starts with '[' and json lines with '{'
+func detectFormat(reader *bufio.Reader) (Format, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return FormatJSON, nil
+			}
+			return "", err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = reader.ReadByte()
+			continue
+		case '{':
+			return FormatJSONL, nil
+		}
+
+		return FormatJSON, nil
+	}
+}
+
+// itemWriter - writes dumped items in a file format
+type itemWriter interface {
+	// begin - writes anything required before the first item
+	begin() error
+	// write - writes an item, index is the number of items written before it
+	write(item map[string]any, index int) error
+	// end - writes anything required after the last item
+	end() error
+}
+
+// newItemWriter - creates an item writer for the output format, defaults to a json array
+func newItemWriter(writer Writer, opts *QueryOpts) itemWriter {
+	switch opts.Format {
+	case FormatJSONL:
+		return &jsonLinesWriter{encoder: json.NewEncoder(writer)}
+	case FormatCSV:
+		return newCSVItemWriter(writer, opts.Attributes, opts.CSVNested)
+	case FormatParquet:
+		return newParquetItemWriter(writer)
+	}
+
+	return &jsonArrayWriter{writer: writer, encoder: json.NewEncoder(writer)}
+}
+
+// streams - whether the format writes each item as it is received, output that is buffered until the end cannot be checkpointed
+func streams(opts *QueryOpts) bool {
+	switch opts.Format {
+	case FormatParquet:
+		return false
+	case FormatCSV:
+		return len(opts.Attributes) > 0
+	}
+
+	return true
+}
+
+// jsonArrayWriter - writes items as a json array
+type jsonArrayWriter struct {
+	writer  Writer
+	encoder *json.Encoder
+}
+
+func (w *jsonArrayWriter) begin() error {
+	_, err := w.writer.WriteString("[\n")
+	return err
+}
+
+func (w *jsonArrayWriter) write(item map[string]any, index int) error {
+	if index > 0 {
+		if _, err := w.writer.WriteString(",\n"); err != nil {
+			return err
+		}
+	}
+
+	return w.encoder.Encode(item)
+}
+
+func (w *jsonArrayWriter) end() error {
+	_, err := w.writer.WriteString("\n]")
+	return err
+}
+
+// jsonLinesWriter - writes items as newline delimited json
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonLinesWriter) begin() error {
+	return nil
+}
+
+func (w *jsonLinesWriter) write(item map[string]any, _ int) error {
+	return w.encoder.Encode(item)
+}
+
+func (w *jsonLinesWriter) end() error {
+	return nil
+}
+
+// itemReader - reads seeded items in a file format
+type itemReader interface {
+	// read - reads the next item, returns io.EOF when there are no more items
+	read() (map[string]types.AttributeValue, error)
+}
+
+// newItemReader - creates an item reader for the input format
+func (s Service) newItemReader(reader *bufio.Reader, format Format, opts *SeedOpts) (itemReader, error) {
+	if _, err := ParseSeedFormat(string(format)); err != nil {
+		return nil, err
+	}
+
+	if format == FormatCSV {
+		return newCSVItemReader(reader, opts.ColumnTypes)
+	}
+
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	if format == FormatJSON {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &jsonItemReader{
+		logger:   s.logger,
+		decoder:  decoder,
+		rawInput: aws.ToBool(opts.RawInput),
+		detect:   opts.RawInput == nil,
+	}, nil
+}
+
+// jsonItemReader - reads items from a json array or json lines.
+// Unless raw input is set, either way, the first item detects whether the input is dynamodb json.
+type jsonItemReader struct {
+	logger   logging.Logger
+	decoder  *json.Decoder
+	rawInput bool
+	detect   bool
+	count    int
+}
+
+func (r *jsonItemReader) read() (map[string]types.AttributeValue, error) {
+	if !r.decoder.More() {
+		return nil, io.EOF
+	}
+
+	var item map[string]any
+	if err := r.decoder.Decode(&item); err != nil {
+		return nil, err
+	}
+
+	r.count++
+	if r.count == 1 && r.detect {
+		r.rawInput = ddb.IsAVItem(item)
+		r.logger.Debug("detected seed format", "raw", r.rawInput)
+	}
+
+	converted, err := marshalSeedItem(item, r.rawInput)
+	if err != nil {
+		return nil, &seedItemError{item: item, err: err}
+	}
+
+	return converted, nil
+}
+
+// marshalSeedItem - marshals a decoded item into attribute values, parsing dynamodb json when the input is raw
+func marshalSeedItem(item map[string]any, rawInput bool) (map[string]types.AttributeValue, error) {
+	if rawInput {
+		return ddb.ParseAVValue(item)
+	}
+
+	return attributevalue.MarshalMap(item)
+}
+
+// itemSpool - spools items to a temporary file as json lines, for formats that can only be written once every item is known
+type itemSpool struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// add - adds an item to the spool, the temporary file is created with the first item
+func (s *itemSpool) add(item map[string]any) error {
+	if s.file == nil {
+		file, err := os.CreateTemp("", "goety-spool-*")
+		if err != nil {
+			return err
+		}
+		s.file = file
+		s.encoder = json.NewEncoder(file)
+	}
+
+	return s.encoder.Encode(item)
+}
+
+// each - calls fn with each spooled item in the order they were added, numbers are decoded as json.Number
+func (s *itemSpool) each(fn func(item map[string]any) error) error {
+	if s.file == nil {
+		return nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(s.file))
+	decoder.UseNumber()
+
+	for decoder.More() {
+		var item map[string]any
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// close - closes and removes the temporary file
+func (s *itemSpool) close() {
+	if s.file == nil {
+		return
+	}
+
+	_ = s.file.Close()
+	_ = os.Remove(s.file.Name())
+}
@@ -0,0 +1,61 @@
+package goety
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestParseFormat(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should parse supported formats", func(t *testing.T) {
+			format, err := ParseFormat("jsonl")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, FormatJSONL, format)
+		}).
+		Test("should return error on unsupported format", func(t *testing.T) {
+			_, err := ParseFormat("xml")
+			odize.AssertTrue(t, errors.Is(err, ErrUnsupportedFormat))
+		}).
+		Test("should return error on a format that cannot be seeded", func(t *testing.T) {
+			_, err := ParseSeedFormat("parquet")
+			odize.AssertTrue(t, errors.Is(err, ErrUnsupportedFormat))
+
+			format, err := ParseSeedFormat("csv")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, FormatCSV, format)
+		}).
+		Test("should return format from file extension", func(t *testing.T) {
+			odize.AssertEqual(t, FormatJSONL, FormatFromPath("dump.ndjson"))
+			odize.AssertEqual(t, FormatJSONL, FormatFromPath("dump.JSONL"))
+			odize.AssertEqual(t, FormatJSON, FormatFromPath("dump.json"))
+			odize.AssertEqual(t, Format(""), FormatFromPath("dump.txt"))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func Test_detectFormat(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should detect json array", func(t *testing.T) {
+			format, err := detectFormat(bufio.NewReader(strings.NewReader("\n  [{}]")))
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, FormatJSON, format)
+		}).
+		Test("should detect json lines", func(t *testing.T) {
+			format, err := detectFormat(bufio.NewReader(strings.NewReader("\n{}\n{}")))
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, FormatJSONL, format)
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
@@ -1,16 +1,17 @@
 package goety
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
@@ -30,144 +31,233 @@ func New(client DynamoClient, logger *slog.Logger, emitter emitter.MessagePublis
 	}
 }
 
-// Purge all items from the given table
+// Purge all items from the given table, or only the items matching a filter expression. Optionally specify the number of segments to scan in parallel.
+// When a partition is provided, only the items of that partition are queried and purged.
+// Table keys are resolved from the table key schema, any keys provided must match the schema.
+// With a checkpoint store, progress is saved after each page and, a resumed purge continues from the last checkpoint.
 //
 // Example:
 //
-//	Purge(ctx, "my-table", TableKeys{ PartitionKey: "pk", SortKey: "sk" })
-func (s Service) Purge(ctx context.Context, tableName string, keys TableKeys) error {
+//	Purge(ctx, "my-table", TableKeys{}, WithSegments(4))
+func (s Service) Purge(ctx context.Context, tableName string, keys TableKeys, opts ...QueryFuncOpts) error {
 	s.emitter.Publish(fmt.Sprintf("scanning table %s for items to purge", tableName))
 	now := time.Now()
 
-	done := false
-	var err error
-	var out *dynamodb.ScanOutput
-	next := ddb.ScanIterator(ctx, s.client)
+	keys, err := s.resolveTableKeys(ctx, tableName, keys)
+	if err != nil {
+		return err
+	}
 
-	deleted := 0
+	queryOpts := WithQueryOptions(opts)
 
-	for !done {
-		out, err, done = next(&dynamodb.ScanInput{
-			TableName:       &tableName,
-			AttributesToGet: []string{keys.PartitionKey, keys.SortKey},
-			Limit:           aws.Int32(defaultBatchSize),
-		})
-		if err != nil {
-			s.logger.Error("could not scan table", "error", err)
-			return err
-		}
+	checkpoint, err := s.loadCheckpoint(tableName, queryOpts)
+	if err != nil {
+		return err
+	}
 
-		if out == nil {
-			break
-		}
+	var mx sync.Mutex
+	resumed := 0
 
-		if len(out.Items) == 0 {
-			break
-		}
+	if queryOpts.Resume {
+		resumed = checkpoint.Items
+		s.emitter.Publish(fmt.Sprintf("resuming purge, deleted %d items before resume", resumed))
+	}
 
-		if s.dryRun {
-			s.logger.Debug("dry run enabled")
-			prettyPrint(out.Items)
-			return nil
+	deleted := resumed
+
+	pageFn := func(ctx context.Context, segment int32, p page) error {
+		if len(p.Items) > 0 {
+			if s.dryRun {
+				s.logger.Debug("dry run enabled")
+				prettyPrint(p.Items)
+				return nil
+			}
+
+			if _, err := s.client.BatchDeleteItems(ctx, tableName, p.Items); err != nil {
+				s.logger.Error("could not batch delete items", "error", err)
+				return err
+			}
 		}
 
-		_, err = s.client.BatchDeleteItems(ctx, tableName, out.Items)
+		mx.Lock()
+		deleted += len(p.Items)
+		err := s.saveCheckpoint(queryOpts.Checkpoint, checkpoint, segment, p.LastEvaluatedKey, deleted, 0)
+		total := deleted
+		mx.Unlock()
+
 		if err != nil {
-			s.logger.Error("could not batch delete items", "error", err)
 			return err
 		}
-		deleted += len(out.Items)
 
-		s.emitter.Publish(fmt.Sprintf("deleted %d items", deleted))
+		s.emitter.Publish(fmt.Sprintf("deleted %d items", total))
+		return nil
+	}
 
+	if queryOpts.Partition != nil {
+		err = s.purgePartition(ctx, tableName, keys, queryOpts, checkpoint, pageFn)
+	} else {
+		err = s.purgeTable(ctx, tableName, keys, queryOpts, checkpoint, pageFn)
+	}
+	if err != nil {
+		return err
 	}
 
 	since := time.Since(now)
 
+	if queryOpts.Resume {
+		s.emitter.Publish(fmt.Sprintf("purge complete, deleted %d items (%d before resume, %d after resume), time taken [%v]", deleted, resumed, deleted-resumed, since))
+		return nil
+	}
+
 	s.emitter.Publish(fmt.Sprintf("purge complete, deleted %d items, time taken [%v]", deleted, since))
 	return nil
 }
 
+// purgeTable - scans the table for the keys of the items to purge
+func (s Service) purgeTable(ctx context.Context, tableName string, keys TableKeys, queryOpts *QueryOpts, checkpoint *Checkpoint, pageFn pageFunc) error {
+	exprs, err := buildExpressions(queryOpts)
+	if err != nil {
+		return err
+	}
+
+	names := exprs.names
+	if names == nil {
+		names = map[string]string{}
+	}
+	projection := keys.projection(names)
+
+	return s.scanPages(ctx, queryOpts.Segments, checkpoint, func() *dynamodb.ScanInput {
+		return &dynamodb.ScanInput{
+			TableName:                 &tableName,
+			ProjectionExpression:      &projection,
+			FilterExpression:          exprs.filter,
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: exprs.values,
+			Limit:                     aws.Int32(defaultBatchSize),
+		}
+	}, pageFn)
+}
+
+// purgePartition - queries the partition for the keys of the items to purge, narrowed by the optional sort key condition
+func (s Service) purgePartition(ctx context.Context, tableName string, keys TableKeys, queryOpts *QueryOpts, checkpoint *Checkpoint, pageFn pageFunc) error {
+	exprs, err := buildExpressions(queryOpts)
+	if err != nil {
+		return err
+	}
+
+	names := exprs.names
+	if names == nil {
+		names = map[string]string{}
+	}
+
+	values := exprs.values
+	if values == nil {
+		values = map[string]types.AttributeValue{}
+	}
+
+	condition, err := partitionCondition(keys, *queryOpts.Partition, queryOpts.SortCondition, names, values)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug("partition provided, querying table", "partition", *queryOpts.Partition, "condition", condition)
+
+	return s.queryPages(ctx, checkpoint, &dynamodb.QueryInput{
+		TableName:                 &tableName,
+		KeyConditionExpression:    &condition,
+		ProjectionExpression:      aws.String(keys.projection(names)),
+		FilterExpression:          exprs.filter,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		Limit:                     aws.Int32(defaultBatchSize),
+	}, pageFn)
+}
+
 // Dump all items from the given table. Optionally specify a list of attributes to extract.
+// When a key condition is provided, the table or index is queried instead of scanned.
+// With a checkpoint store, progress is saved after each page and, a resumed dump continues writing after the last checkpoint.
+// Items are written as a json array unless another format is provided. Masks are applied before items are flattened, so masked attributes keep their type.
 //
 // Example:
 //
-//	Dump(ctx, "my-table", "path/to/file.json", []string{"attr1", "attr2"})
+//	Dump(ctx, "my-table", file, WithAttrs([]string{"attr1", "attr2"}), WithKeyCondition("pk = :pk"))
 func (s Service) Dump(ctx context.Context, tableName string, writer Writer, opts ...QueryFuncOpts) error {
 	s.emitter.Publish(fmt.Sprintf("dumping table %s", tableName))
 
-	encoder := json.NewEncoder(writer)
-	_, err := writer.WriteString("[\n")
+	queryOpts := WithQueryOptions(opts)
+
+	if queryOpts.Checkpoint != nil && !streams(queryOpts) {
+		return fmt.Errorf("%w: %s without attributes", ErrCheckpointUnsupported, queryOpts.Format)
+	}
+
+	checkpoint, err := s.loadCheckpoint(tableName, queryOpts)
 	if err != nil {
-		s.logger.Error("Error writing to buffer:", "error", err)
 		return err
 	}
 
-	defer func() {
-		_, err := writer.WriteString("\n]")
+	var mx sync.Mutex
+	itemsScanned := 0
+	output := &offsetWriter{writer: writer}
+	items := newItemWriter(output, queryOpts)
+
+	if queryOpts.Resume {
+		itemsScanned = checkpoint.Items
+		output.offset = checkpoint.Offset
+		s.emitter.Publish(fmt.Sprintf("resuming dump after %d items", itemsScanned))
+	} else {
+		err = items.begin()
 		if err != nil {
 			s.logger.Error("Error writing to buffer:", "error", err)
-			return
+			return err
 		}
-	}()
-
-	queryOpts := WithQueryOptions(opts)
-
-	done := false
-	var output *dynamodb.ScanOutput
-	next := ddb.ScanIterator(ctx, s.client)
-
-	itemsScanned := 0
+	}
 
-	for !done {
-		output, err, done = next(
-			&dynamodb.ScanInput{
-				TableName:                 &tableName,
-				ProjectionExpression:      queryOpts.ProjectedExpressions,
-				FilterExpression:          queryOpts.FilterExpression,
-				ExpressionAttributeNames:  queryOpts.FilterNameAttributes,
-				ExpressionAttributeValues: queryOpts.FilterNameValues,
-			})
-		if err != nil && !errors.Is(err, ddb.ErrNoItems) {
-			s.logger.Error("could not scan table", "error", err)
+	pageFn := func(ctx context.Context, segment int32, p page) error {
+		if err := queryOpts.Transform.ApplyAll(p.Items); err != nil {
+			s.logger.Error("could not transform items", "error", err)
 			return err
 		}
 
-		if output == nil {
-			break
+		if err := queryOpts.Mask.ApplyAll(p.Items); err != nil {
+			s.logger.Error("could not mask items", "error", err)
+			return err
 		}
 
-		items, err := transformDumpOutput(output.Items, queryOpts.RawOutput)
+		transformed, err := transformDumpOutput(p.Items, queryOpts.RawOutput, queryOpts.NumberMode)
 		if err != nil {
 			s.logger.Error("could not transform items", "error", err)
 			return err
 		}
 
-		for i, item := range items {
-			if s.dryRun {
-				s.logger.Debug("dry run enabled")
-				prettyPrint(item)
-				continue
-			}
-
-			if i > 0 || itemsScanned > 0 {
-				_, err = writer.WriteString(",\n")
-				if err != nil {
-					s.logger.Error("could not write to buffer", "error", err)
-					return err
-				}
-			}
+		mx.Lock()
+		err = s.writeItems(items, transformed, itemsScanned)
+		if err == nil {
+			itemsScanned += len(transformed)
+			err = s.saveCheckpoint(queryOpts.Checkpoint, checkpoint, segment, p.LastEvaluatedKey, itemsScanned, output.offset)
+		}
+		total := itemsScanned
+		mx.Unlock()
 
-			err = encoder.Encode(item)
-			if err != nil {
-				s.logger.Error("could not encode items", "error", err)
-				return err
-			}
+		if err != nil {
+			return err
 		}
 
-		itemsScanned += len(items)
-		s.emitter.Publish(fmt.Sprintf("scanned %d items", itemsScanned))
+		s.emitter.Publish(fmt.Sprintf("scanned %d items", total))
+		return nil
+	}
+
+	err = s.readPages(ctx, tableName, queryOpts, checkpoint, pageFn)
+	if err != nil {
+		if endErr := items.end(); endErr != nil {
+			s.logger.Error("Error writing to buffer:", "error", endErr)
+		}
+		return err
+	}
 
+	// formats that spool items, such as parquet and csv without attributes, write the file when the writer ends
+	if err = items.end(); err != nil {
+		s.logger.Error("Error writing to buffer:", "error", err)
+		return err
 	}
 
 	s.emitter.Publish(fmt.Sprintf("scanned %d items", itemsScanned))
@@ -185,59 +275,290 @@ func (s Service) Dump(ctx context.Context, tableName string, writer Writer, opts
 	return nil
 }
 
-// Seed a table with items from a json file
+// Seed a table with items from a json array, newline delimited json or csv file. When no format is provided, json or json lines is detected from the file.
+// Files written by a raw output dump are detected and seeded without transformation, gzip and zstd compressed input is decompressed.
+// An item that repeats the key of an item in the current batch starts a new batch, so a later item overwrites the earlier one.
 //
 // Example:
 //
-//	Seed(ctx, "my-table", "path/to/file.json")
-func (s Service) Seed(ctx context.Context, tableName string, reader io.Reader) error {
+//	Seed(ctx, "my-table", file, WithRawInput(true))
+func (s Service) Seed(ctx context.Context, tableName string, reader io.Reader, opts ...SeedFuncOpts) error {
 	s.emitter.Publish(fmt.Sprintf("putting items to table %s", tableName))
 
-	decoder := json.NewDecoder(reader)
-	_, err := decoder.Token()
+	seedOpts := WithSeedOptions(opts)
+
+	buffered, closeReader, err := decompress(bufio.NewReader(reader))
+	if err != nil {
+		s.logger.Error("could not decompress seed input", "error", err)
+		return err
+	}
+	defer func() {
+		_ = closeReader()
+	}()
+
+	format := seedOpts.Format
+	if format == "" {
+		detected, err := detectFormat(buffered)
+		if err != nil {
+			s.logger.Error("could not detect seed format", "error", err)
+			return err
+		}
+		format = detected
+		s.logger.Debug("detected seed file format", "format", format)
+	}
+
+	items, err := s.newItemReader(buffered, format, seedOpts)
 	if err != nil {
-		s.logger.Error("could not read starting token", "error", err)
+		s.logger.Error("could not read seed input", "error", err)
 		return err
 	}
 
+	// a batch cannot put two items with the same key, the key schema finds items that repeat a key in the batch
+	var keys TableKeys
 	if s.dryRun {
 		s.logger.Debug("dry run enabled")
+	} else {
+		keys, err = s.batchTableKeys(ctx, tableName)
+		if err != nil {
+			return err
+		}
 	}
 
 	itemCount := 0
-	for decoder.More() {
-		var item map[string]any
-		err = decoder.Decode(&item)
+	failed := 0
+	batch := []map[string]types.AttributeValue{}
+	indexes := []int{}
+	batchKeys := newBatchKeys(keys)
+
+	for {
+		item, err := items.read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
 		if err != nil {
-			s.logger.Error("could not decode item", "error", err)
-			return err
+			var itemErr *seedItemError
+			if !seedOpts.ContinueOnError || !errors.As(err, &itemErr) {
+				s.logger.Error("could not read item", "error", err)
+				return err
+			}
+
+			itemCount++
+			if err = s.deadLetter(seedOpts, &failed, DeadLetter{Index: itemCount, Item: itemErr.item, Error: err.Error()}); err != nil {
+				return err
+			}
+			continue
 		}
 
 		itemCount++
 
+		if err = seedOpts.Transform.Apply(item); err != nil {
+			if !seedOpts.ContinueOnError {
+				s.logger.Error("could not transform item", "error", err)
+				return err
+			}
+
+			if err = s.deadLetter(seedOpts, &failed, DeadLetter{Index: itemCount, Item: deadLetterItem(item), Error: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if s.dryRun {
-			s.logger.Debug("dry run enabled")
 			prettyPrint(item)
 			continue
 		}
 
-		payload, err := attributevalue.MarshalMap(item)
-		if err != nil {
-			s.logger.Error("could not marshal item", "error", err)
+		if !batchKeys.add(item) {
+			s.logger.Debug("item repeats a key in the batch, putting the batch", "index", itemCount)
+			if err = s.seedBatch(ctx, tableName, seedOpts, batch, indexes, &failed); err != nil {
+				return err
+			}
+			batch = []map[string]types.AttributeValue{}
+			indexes = []int{}
+			batchKeys.reset()
+			batchKeys.add(item)
+		}
+
+		batch = append(batch, item)
+		indexes = append(indexes, itemCount)
+		if len(batch) < defaultBatchSize {
+			continue
+		}
+
+		if err = s.seedBatch(ctx, tableName, seedOpts, batch, indexes, &failed); err != nil {
 			return err
 		}
+		batch = []map[string]types.AttributeValue{}
+		indexes = []int{}
+		batchKeys.reset()
 
-		s.logger.Debug("putting item", "item", payload)
+		s.emitter.Publish(fmt.Sprintf("inserted %d items", itemCount-failed))
+	}
 
-		if _, err := s.client.Put(ctx, &dynamodb.PutItemInput{
-			TableName: &tableName,
-			Item:      payload,
-		}); err != nil {
+	if len(batch) > 0 {
+		if err := s.seedBatch(ctx, tableName, seedOpts, batch, indexes, &failed); err != nil {
 			return err
 		}
 	}
 
+	if failed > 0 {
+		s.emitter.Publish(fmt.Sprintf("seed complete with %d items inserted, %d items failed", itemCount-failed, failed))
+		s.logger.Info("seed complete", "items", itemCount-failed, "failed", failed)
+		return &SeedError{Succeeded: itemCount - failed, Failed: failed}
+	}
+
 	s.emitter.Publish(fmt.Sprintf("seed complete with %d items inserted", itemCount))
+	s.logger.Info("seed complete", "items", itemCount, "failed", 0)
+	return nil
+}
+
+// seedBatch - puts a batch of seeded items, indexes are the positions of the items in the seed input.
+// When continuing on error, a batch that fails is put item by item so only the items that fail are dead lettered.
+func (s Service) seedBatch(ctx context.Context, tableName string, seedOpts *SeedOpts, batch []map[string]types.AttributeValue, indexes []int, failed *int) error {
+	err := s.putBatch(ctx, tableName, batch)
+	if err == nil || !seedOpts.ContinueOnError || ctx.Err() != nil {
+		return err
+	}
+
+	s.logger.Debug("batch failed, putting items individually", "items", len(batch))
+
+	for i, item := range batch {
+		if _, err = s.client.Put(ctx, &dynamodb.PutItemInput{TableName: aws.String(tableName), Item: item}); err == nil {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err = s.deadLetter(seedOpts, failed, DeadLetter{Index: indexes[i], Item: deadLetterItem(item), Error: err.Error()}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deadLetter - counts the failed item and adds it to the dead letter store, when one is configured
+func (s Service) deadLetter(seedOpts *SeedOpts, failed *int, letter DeadLetter) error {
+	*failed++
+	s.logger.Debug("could not seed item", "index", letter.Index, "error", letter.Error)
+
+	if seedOpts.DeadLetter == nil {
+		return nil
+	}
+
+	if err := seedOpts.DeadLetter.Add(letter); err != nil {
+		s.logger.Error("could not add dead letter", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Copy all items from the source table to the target table, items are written as each page is read.
+// The service client reads the source table and, the target client writes the target table, so each table may be in a different account, region or endpoint.
+// The filter, projection, index and key condition options are applied to the source table.
+//
+// Example:
+//
+//	Copy(ctx, "prod-table", devClient, "dev-table", WithSegments(4))
+func (s Service) Copy(ctx context.Context, sourceTable string, target DynamoClient, targetTable string, opts ...QueryFuncOpts) error {
+	s.emitter.Publish(fmt.Sprintf("copying table %s to %s", sourceTable, targetTable))
+	now := time.Now()
+
+	queryOpts := WithQueryOptions(opts)
+
+	// transforms can give items the same key, a batch cannot put two items with the same key
+	var keys TableKeys
+	if !s.dryRun {
+		targetService := s
+		targetService.client = target
+
+		var err error
+		if keys, err = targetService.batchTableKeys(ctx, targetTable); err != nil {
+			return err
+		}
+	}
+
+	var mx sync.Mutex
+	copied := 0
+
+	pageFn := func(ctx context.Context, segment int32, p page) error {
+		if err := queryOpts.Transform.ApplyAll(p.Items); err != nil {
+			s.logger.Error("could not transform items", "error", err)
+			return err
+		}
+
+		if s.dryRun {
+			s.logger.Debug("dry run enabled")
+			prettyPrint(p.Items)
+		} else {
+			for _, batch := range newBatchKeys(keys).batches(p.Items) {
+				if _, err := target.BatchPutItems(ctx, targetTable, batch); err != nil {
+					s.logger.Error("could not batch put items", "error", err)
+					return err
+				}
+			}
+		}
+
+		mx.Lock()
+		copied += len(p.Items)
+		total := copied
+		mx.Unlock()
+
+		s.emitter.Publish(fmt.Sprintf("copied %d items, %.0f items/s", total, throughput(total, time.Since(now))))
+		return nil
+	}
+
+	if err := s.readPages(ctx, sourceTable, queryOpts, nil, pageFn); err != nil {
+		return err
+	}
+
+	since := time.Since(now)
+	s.emitter.Publish(fmt.Sprintf("copy complete, copied %d items, time taken [%v], %.0f items/s", copied, since, throughput(copied, since)))
+	s.logger.Info("copy complete", "items", copied)
+	return nil
+}
+
+// throughput - returns the items processed per second
+func throughput(items int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(items) / elapsed.Seconds()
+}
+
+// putBatch - writes a batch of items to the table
+func (s Service) putBatch(ctx context.Context, tableName string, batch []map[string]types.AttributeValue) error {
+	s.logger.Debug("putting items", "items", len(batch))
+
+	if _, err := s.client.BatchPutItems(ctx, tableName, batch); err != nil {
+		s.logger.Error("could not batch put items", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// writeItems - writes items in the dump format, written is the number of items already written.
+// Callers writing from multiple segments must serialise calls to writeItems.
+func (s Service) writeItems(writer itemWriter, items []map[string]any, written int) error {
+	for i, item := range items {
+		if s.dryRun {
+			s.logger.Debug("dry run enabled")
+			prettyPrint(item)
+			continue
+		}
+
+		err := writer.write(item, written+i)
+		if err != nil {
+			s.logger.Error("could not encode items", "error", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -251,11 +572,11 @@ func prettyPrint(v any) {
 	fmt.Println(string(data))
 }
 
-func transformDumpOutput(attrData []map[string]types.AttributeValue, rawOutput bool) ([]map[string]any, error) {
+func transformDumpOutput(attrData []map[string]types.AttributeValue, rawOutput bool, numberMode ddb.NumberMode) ([]map[string]any, error) {
 	out := []map[string]any{}
 
 	if !rawOutput {
-		items, transformErr := ddb.FlattenAttrList(attrData)
+		items, transformErr := ddb.FlattenAttrListWithMode(attrData, numberMode)
 		if transformErr != nil {
 			return out, transformErr
 		}
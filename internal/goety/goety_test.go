@@ -1,13 +1,20 @@
 package goety
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
 	"github.com/code-gorilla-au/goety/internal/logging"
 	"github.com/code-gorilla-au/odize"
 )
@@ -16,7 +23,9 @@ type mockEmitter struct {
 	publishFunc func(message string)
 }
 
-func (m *mockEmitter) Publish(string) {}
+func (m *mockEmitter) Publish(message string) {
+	m.publishFunc(message)
+}
 
 func TestService_Purge(t *testing.T) {
 	var client DynamoClientMock
@@ -47,6 +56,9 @@ func TestService_Purge(t *testing.T) {
 				callBatchDelete++
 				return &dynamodb.BatchWriteItemOutput{}, nil
 			},
+			DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", "sk"), nil
+			},
 		}
 
 		service = Service{
@@ -109,12 +121,225 @@ func TestService_Purge(t *testing.T) {
 			odize.AssertNoError(t, err)
 			odize.AssertEqual(t, 0, callBatchDelete)
 		}).
+		Test("should resolve keys from the table key schema", func(t *testing.T) {
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("id", ""), nil
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{})
+			odize.AssertNoError(t, err)
+
+			input := client.ScanCalls()[0].Input
+			odize.AssertEqual(t, "#goetyKey0", *input.ProjectionExpression)
+			odize.AssertEqual(t, map[string]string{"#goetyKey0": "id"}, input.ExpressionAttributeNames)
+		}).
+		Test("should purge items matching the filter, projecting the key attributes", func(t *testing.T) {
+			err := service.Purge(ctx, "my-table", TableKeys{},
+				WithFilterExpression("begins_with(#pk, :tenant)"),
+				WithFilterNameAttrs("#pk=pk"),
+				WithFilterNameValues(":tenant=tenant-1"),
+			)
+			odize.AssertNoError(t, err)
+
+			input := client.ScanCalls()[0].Input
+			odize.AssertEqual(t, "begins_with(#pk, :tenant)", *input.FilterExpression)
+			odize.AssertEqual(t, "#goetyKey0, #goetyKey1", *input.ProjectionExpression)
+			odize.AssertEqual(t, map[string]string{"#pk": "pk", "#goetyKey0": "pk", "#goetyKey1": "sk"}, input.ExpressionAttributeNames)
+			odize.AssertEqual(t, "tenant-1", input.ExpressionAttributeValues[":tenant"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should return error if keys do not match the table key schema", func(t *testing.T) {
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("id", ""), nil
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{PartitionKey: "pk", SortKey: "sk"})
+			odize.AssertTrue(t, errors.Is(err, ErrTableKeyMismatch))
+			odize.AssertEqual(t, 0, callScanAll)
+		}).
+		Test("should query the partition and delete its items", func(t *testing.T) {
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				output := describeTableOutput("pk", "sk")
+				output.Table.AttributeDefinitions = []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeN},
+				}
+				return output, nil
+			}
+			client.QueryFunc = func(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						{"pk": &types.AttributeValueMemberS{Value: "tenant-1"}, "sk": &types.AttributeValueMemberN{Value: "1"}},
+					},
+				}, nil
+			}
+
+			sort, err := ParseSortCondition("between 1 and 10")
+			odize.AssertNoError(t, err)
+
+			err = service.Purge(ctx, "my-table", TableKeys{}, WithPartition("tenant-1"), WithSortCondition(sort))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 0, callScanAll)
+			odize.AssertEqual(t, 1, callBatchDelete)
+
+			input := client.QueryCalls()[0].Input
+			odize.AssertEqual(t, "#goetyKey0 = :goetyPartition AND #goetyKey1 BETWEEN :goetySort0 AND :goetySort1", *input.KeyConditionExpression)
+			odize.AssertEqual(t, "#goetyKey0, #goetyKey1", *input.ProjectionExpression)
+			odize.AssertEqual(t, map[string]string{"#goetyKey0": "pk", "#goetyKey1": "sk"}, input.ExpressionAttributeNames)
+			odize.AssertEqual(t, "tenant-1", input.ExpressionAttributeValues[":goetyPartition"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, "10", input.ExpressionAttributeValues[":goetySort1"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should return error if the sort key value does not match the key type", func(t *testing.T) {
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				output := describeTableOutput("pk", "sk")
+				output.Table.AttributeDefinitions = []types.AttributeDefinition{
+					{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeN},
+				}
+				return output, nil
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{}, WithPartition("tenant-1"), WithSortCondition(&SortCondition{Operator: SortGreater, Values: []string{"order#"}}))
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidKeyValue))
+			odize.AssertEqual(t, 0, len(client.QueryCalls()))
+		}).
+		Test("should purge items matching the where condition", func(t *testing.T) {
+			where, err := ParseWhere("size(tags) > 2")
+			odize.AssertNoError(t, err)
+
+			err = service.Purge(ctx, "my-table", TableKeys{}, WithWhere(where))
+			odize.AssertNoError(t, err)
+
+			input := client.ScanCalls()[0].Input
+			odize.AssertEqual(t, "size (#0) > :0", *input.FilterExpression)
+			odize.AssertEqual(t, "#goetyKey0, #goetyKey1", *input.ProjectionExpression)
+			odize.AssertEqual(t, map[string]string{"#0": "tags", "#goetyKey0": "pk", "#goetyKey1": "sk"}, input.ExpressionAttributeNames)
+		}).
+		Test("should return error if describe table fails", func(t *testing.T) {
+			expectedErr := errors.New("describe error")
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return nil, expectedErr
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{})
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
+		Test("should resume purge from checkpoint", func(t *testing.T) {
+			expectedErr := errors.New("credentials expired")
+			lastKey := map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: "pk"},
+				"sk": &types.AttributeValueMemberS{Value: "sk"},
+			}
+			store := &mockCheckpointStore{}
+
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				if input.ExclusiveStartKey != nil {
+					return nil, expectedErr
+				}
+				return &dynamodb.ScanOutput{
+					Items:            []map[string]types.AttributeValue{lastKey},
+					LastEvaluatedKey: lastKey,
+				}, nil
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{}, WithCheckpoint(store))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+			odize.AssertEqual(t, 1, store.checkpoint.Items)
+
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				odize.AssertEqual(t, lastKey, input.ExclusiveStartKey)
+				return &dynamodb.ScanOutput{
+					Items: []map[string]types.AttributeValue{lastKey, lastKey},
+				}, nil
+			}
+
+			err = service.Purge(ctx, "my-table", TableKeys{}, WithCheckpoint(store), WithResume(true))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 3, store.checkpoint.Items)
+			odize.AssertEqual(t, 2, callBatchDelete)
+		}).
+		Test("should skip empty pages and continue scanning", func(t *testing.T) {
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				callScanAll++
+				if callScanAll == 1 {
+					return &dynamodb.ScanOutput{
+						LastEvaluatedKey: map[string]types.AttributeValue{
+							"pk": &types.AttributeValueMemberS{Value: "pk"},
+						},
+					}, nil
+				}
+				return &dynamodb.ScanOutput{
+					Items: []map[string]types.AttributeValue{
+						{"pk": &types.AttributeValueMemberS{Value: "pk"}},
+					},
+				}, nil
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{})
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 2, callScanAll)
+			odize.AssertEqual(t, 1, callBatchDelete)
+		}).
+		Test("should purge each segment", func(t *testing.T) {
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("pk-%d", *input.Segment)},
+							"sk": &types.AttributeValueMemberS{Value: "sk"},
+						},
+					},
+				}, nil
+			}
+			client.BatchDeleteItemsFunc = func(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{PartitionKey: "pk", SortKey: "sk"}, WithSegments(3))
+			odize.AssertNoError(t, err)
+
+			segments := map[int32]bool{}
+			for _, call := range client.ScanCalls() {
+				odize.AssertEqual(t, int32(3), *call.Input.TotalSegments)
+				segments[*call.Input.Segment] = true
+			}
+			odize.AssertEqual(t, 3, len(segments))
+			odize.AssertEqual(t, 3, len(client.BatchDeleteItemsCalls()))
+		}).
+		Test("should return error if a segment fails", func(t *testing.T) {
+			expectedErr := errors.New("segment error")
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				if *input.Segment == 1 {
+					return nil, expectedErr
+				}
+				return &dynamodb.ScanOutput{}, nil
+			}
+
+			err := service.Purge(ctx, "my-table", TableKeys{PartitionKey: "pk", SortKey: "sk"}, WithSegments(2))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
 		Run()
 
 	odize.AssertNoError(t, err)
 
 }
 
+func describeTableOutput(partitionKey string, sortKey string) *dynamodb.DescribeTableOutput {
+	schema := []types.KeySchemaElement{
+		{AttributeName: aws.String(partitionKey), KeyType: types.KeyTypeHash},
+	}
+	if sortKey != "" {
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(sortKey), KeyType: types.KeyTypeRange})
+	}
+
+	return &dynamodb.DescribeTableOutput{
+		Table: &types.TableDescription{
+			KeySchema: schema,
+		},
+	}
+}
+
 type mockWriter struct {
 	writeFunc       func(p []byte) (n int, err error)
 	writeStringFunc func(s string) (n int, err error)
@@ -244,11 +469,44 @@ func TestService_Dump(t *testing.T) {
 			fmt.Println("ooooo", err)
 			odize.AssertTrue(t, errors.Is(err, expectedErr))
 		}).
+		Test("should return error if parquet file write fails", func(t *testing.T) {
+			expectedErr := errors.New("write file error")
+			published := []string{}
+			service.emitter = &mockEmitter{
+				publishFunc: func(message string) {
+					published = append(published, message)
+				},
+			}
+			writer.writeFunc = func(data []byte) (int, error) {
+				return 0, expectedErr
+			}
+
+			err := service.Dump(ctx, "my-table", &writer, WithFormat(FormatParquet))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+			odize.AssertFalse(t, slices.Contains(published, "dump complete"))
+		}).
+		Test("should return error if csv file write fails without attributes", func(t *testing.T) {
+			expectedErr := errors.New("write file error")
+			published := []string{}
+			service.emitter = &mockEmitter{
+				publishFunc: func(message string) {
+					published = append(published, message)
+				},
+			}
+			writer.writeFunc = func(data []byte) (int, error) {
+				return 0, expectedErr
+			}
+
+			err := service.Dump(ctx, "my-table", &writer, WithFormat(FormatCSV))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+			odize.AssertFalse(t, slices.Contains(published, "dump complete"))
+		}).
 		Test("should dump items with attributes", func(t *testing.T) {
 			attrExp := []string{"attr1", "attr2"}
 
 			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
-				odize.AssertEqual(t, "attr1, attr2", *input.ProjectionExpression)
+				odize.AssertEqual(t, "#0, #1", *input.ProjectionExpression)
+				odize.AssertEqual(t, map[string]string{"#0": "attr1", "#1": "attr2"}, input.ExpressionAttributeNames)
 
 				return &dynamodb.ScanOutput{
 					Items: []map[string]types.AttributeValue{
@@ -264,8 +522,642 @@ func TestService_Dump(t *testing.T) {
 			err := service.Dump(ctx, "my-table", &writer, WithAttrs(attrExp))
 			odize.AssertNoError(t, err)
 		}).
+		Test("should output exact numbers", func(t *testing.T) {
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"id": &types.AttributeValueMemberN{Value: "12345678901234567890"},
+						},
+					},
+				}, nil
+			}
+			writer.writeFunc = func(p []byte) (n int, err error) {
+				odize.AssertEqual(t, "{\"id\":12345678901234567890}\n", string(p))
+				return len(p), nil
+			}
+
+			err := service.Dump(ctx, "my-table", &writer)
+			odize.AssertNoError(t, err)
+		}).
+		Test("should output numbers as strings", func(t *testing.T) {
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"id": &types.AttributeValueMemberN{Value: "12345678901234567890"},
+						},
+					},
+				}, nil
+			}
+			writer.writeFunc = func(p []byte) (n int, err error) {
+				odize.AssertEqual(t, "{\"id\":\"12345678901234567890\"}\n", string(p))
+				return len(p), nil
+			}
+
+			err := service.Dump(ctx, "my-table", &writer, WithNumberMode(ddb.NumberModeString))
+			odize.AssertNoError(t, err)
+		}).
+		Test("should query items with key condition", func(t *testing.T) {
+			client.QueryFunc = func(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+				odize.AssertEqual(t, "pk = :pk", *input.KeyConditionExpression)
+				odize.AssertEqual(t, "gsi1", *input.IndexName)
+				odize.AssertEqual(t, "pk", input.ExpressionAttributeValues[":pk"].(*types.AttributeValueMemberS).Value)
+
+				return &dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"pk": &types.AttributeValueMemberS{Value: "pk"},
+						},
+					},
+				}, nil
+			}
+
+			buf := bytes.Buffer{}
+			err := service.Dump(ctx, "my-table", &buf,
+				WithKeyCondition("pk = :pk"),
+				WithIndex("gsi1"),
+				WithFilterNameValues(":pk=pk"),
+			)
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 0, callScanAll)
+			odize.AssertEqual(t, 1, len(client.QueryCalls()))
+			odize.AssertEqual(t, "[\n{\"pk\":\"pk\"}\n\n]", buf.String())
+		}).
+		Test("should return error if query fails", func(t *testing.T) {
+			expectedErr := errors.New("query error")
+			client.QueryFunc = func(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+				return nil, expectedErr
+			}
+
+			err := service.Dump(ctx, "my-table", &writer, WithKeyCondition("pk = :pk"))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
+		Test("should resume dump from checkpoint", func(t *testing.T) {
+			expectedErr := errors.New("credentials expired")
+			lastKey := map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: "pk-1"},
+			}
+			store := &mockCheckpointStore{}
+
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				if input.ExclusiveStartKey != nil {
+					return nil, expectedErr
+				}
+				return &dynamodb.ScanOutput{
+					Items:            []map[string]types.AttributeValue{lastKey},
+					LastEvaluatedKey: lastKey,
+				}, nil
+			}
+
+			buf := bytes.Buffer{}
+			err := service.Dump(ctx, "my-table", &buf, WithCheckpoint(store))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+			odize.AssertEqual(t, 1, store.checkpoint.Items)
+
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				odize.AssertEqual(t, lastKey, input.ExclusiveStartKey)
+				return &dynamodb.ScanOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"pk": &types.AttributeValueMemberS{Value: "pk-2"},
+						},
+					},
+				}, nil
+			}
+
+			buf.Truncate(int(store.checkpoint.Offset))
+			err = service.Dump(ctx, "my-table", &buf, WithCheckpoint(store), WithResume(true))
+			odize.AssertNoError(t, err)
+
+			var items []map[string]any
+			odize.AssertNoError(t, json.Unmarshal(buf.Bytes(), &items))
+			odize.AssertEqual(t, []map[string]any{{"pk": "pk-1"}, {"pk": "pk-2"}}, items)
+			odize.AssertEqual(t, 2, store.checkpoint.Items)
+			odize.AssertTrue(t, store.checkpoint.Segments[0].Done)
+		}).
+		Test("should return error when resuming without a checkpoint", func(t *testing.T) {
+			err := service.Dump(ctx, "my-table", &writer, WithResume(true))
+			odize.AssertTrue(t, errors.Is(err, ErrNoCheckpoint))
+			odize.AssertEqual(t, 0, callScanAll)
+		}).
+		Test("should write items from all segments as a single json array", func(t *testing.T) {
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("pk-%d", *input.Segment)},
+						},
+						{
+							"pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("pk-%d", *input.Segment)},
+						},
+					},
+				}, nil
+			}
+
+			buf := bytes.Buffer{}
+			err := service.Dump(ctx, "my-table", &buf, WithSegments(4))
+			odize.AssertNoError(t, err)
+
+			var items []map[string]any
+			odize.AssertNoError(t, json.Unmarshal(buf.Bytes(), &items))
+			odize.AssertEqual(t, 8, len(items))
+		}).
+		Test("should output csv", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := service.Dump(ctx, "my-table", &buf, WithFormat(FormatCSV))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, "pk,sk\npk,sk\n", buf.String())
+		}).
+		Test("should return error on checkpoint with csv derived header", func(t *testing.T) {
+			err := service.Dump(ctx, "my-table", &bytes.Buffer{}, WithFormat(FormatCSV), WithCheckpoint(&mockCheckpointStore{}))
+			odize.AssertTrue(t, errors.Is(err, ErrCheckpointUnsupported))
+			odize.AssertEqual(t, 0, callScanAll)
+		}).
+		Test("should output parquet", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := service.Dump(ctx, "my-table", &buf, WithFormat(FormatParquet))
+			odize.AssertNoError(t, err)
+
+			odize.AssertTrue(t, bytes.HasPrefix(buf.Bytes(), []byte("PAR1")))
+		}).
+		Test("should mask items before they are written", func(t *testing.T) {
+			mask, err := ParseMaskConfig(strings.NewReader("masks:\n  - attribute: sk\n    strategy: redact\n"))
+			odize.AssertNoError(t, err)
+
+			buf := bytes.Buffer{}
+			err = service.Dump(ctx, "my-table", &buf, WithFormat(FormatJSONL), WithMask(mask))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, "{\"pk\":\"pk\",\"sk\":\"REDACTED\"}\n", buf.String())
+		}).
+		Test("should output one item per line as json lines", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := service.Dump(ctx, "my-table", &buf, WithFormat(FormatJSONL))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, "{\"pk\":\"pk\",\"sk\":\"sk\"}\n", buf.String())
+		}).
 		Run()
 
 	odize.AssertNoError(t, err)
 
 }
+
+func TestService_Seed(t *testing.T) {
+	var client DynamoClientMock
+	var service Service
+	logger := logging.New(true)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	group := odize.NewGroup(t, nil)
+
+	group.BeforeEach(func() {
+		client = DynamoClientMock{
+			BatchPutItemsFunc: func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			},
+			DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", "sk"), nil
+			},
+		}
+
+		service = Service{
+			client: &client,
+			dryRun: false,
+			logger: logger,
+			emitter: &mockEmitter{
+				publishFunc: func(message string) {},
+			},
+		}
+	})
+
+	seedFile := func(count int) *bytes.Buffer {
+		items := []map[string]any{}
+		for i := 0; i < count; i++ {
+			items = append(items, map[string]any{"pk": fmt.Sprintf("pk-%d", i), "sk": "sk"})
+		}
+
+		data, _ := json.Marshal(items)
+		return bytes.NewBuffer(data)
+	}
+
+	err := group.
+		Test("should put items in batches of 25", func(t *testing.T) {
+			err := service.Seed(ctx, "my-table", seedFile(60))
+			odize.AssertNoError(t, err)
+
+			calls := client.BatchPutItemsCalls()
+			odize.AssertEqual(t, 3, len(calls))
+			odize.AssertEqual(t, 25, len(calls[0].Items))
+			odize.AssertEqual(t, 25, len(calls[1].Items))
+			odize.AssertEqual(t, 10, len(calls[2].Items))
+		}).
+		Test("should put the batch before an item that repeats a key in it", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":\"pk-0\",\"sk\":\"sk\",\"v\":1}\n{\"pk\":\"pk-1\",\"sk\":\"sk\"}\n{\"pk\":\"pk-0\",\"sk\":\"sk\",\"v\":2}\n{\"pk\":\"pk-0\",\"sk\":\"other\"}\n")
+
+			err := service.Seed(ctx, "my-table", file)
+			odize.AssertNoError(t, err)
+
+			calls := client.BatchPutItemsCalls()
+			odize.AssertEqual(t, 2, len(calls))
+			odize.AssertEqual(t, 2, len(calls[0].Items))
+			odize.AssertEqual(t, 2, len(calls[1].Items))
+			odize.AssertEqual(t, "2", calls[1].Items[0]["v"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should compare number keys by value", func(t *testing.T) {
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", ""), nil
+			}
+			file := bytes.NewBufferString("{\"pk\":1}\n{\"pk\":1.0}\n{\"pk\":10}\n")
+
+			err := service.Seed(ctx, "my-table", file)
+			odize.AssertNoError(t, err)
+
+			calls := client.BatchPutItemsCalls()
+			odize.AssertEqual(t, 2, len(calls))
+			odize.AssertEqual(t, 1, len(calls[0].Items))
+			odize.AssertEqual(t, 2, len(calls[1].Items))
+		}).
+		Test("should seed without splitting batches if not permitted to describe the table", func(t *testing.T) {
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+			}
+
+			err := service.Seed(ctx, "my-table", seedFile(30))
+			odize.AssertNoError(t, err)
+
+			calls := client.BatchPutItemsCalls()
+			odize.AssertEqual(t, 2, len(calls))
+			odize.AssertEqual(t, 25, len(calls[0].Items))
+		}).
+		Test("should return error if describe table fails", func(t *testing.T) {
+			expectedErr := errors.New("describe error")
+			client.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return nil, expectedErr
+			}
+
+			err := service.Seed(ctx, "my-table", seedFile(1))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
+		Test("should marshal items to attribute values", func(t *testing.T) {
+			err := service.Seed(ctx, "my-table", seedFile(1))
+			odize.AssertNoError(t, err)
+
+			item := client.BatchPutItemsCalls()[0].Items[0]
+			odize.AssertEqual(t, "pk-0", item["pk"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should not put items on dry run", func(t *testing.T) {
+			service.dryRun = true
+
+			err := service.Seed(ctx, "my-table", seedFile(30))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 0, len(client.BatchPutItemsCalls()))
+		}).
+		Test("should return error if batch put fails", func(t *testing.T) {
+			expectedErr := errors.New("batch put error")
+			client.BatchPutItemsFunc = func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+				return nil, expectedErr
+			}
+
+			err := service.Seed(ctx, "my-table", seedFile(1))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
+		Test("should seed numbers without loss of precision", func(t *testing.T) {
+			file := bytes.NewBufferString(`[{"pk":"pk","id":12345678901234567890,"price":0.10000000000000000001}]`)
+
+			err := service.Seed(ctx, "my-table", file)
+			odize.AssertNoError(t, err)
+
+			item := client.BatchPutItemsCalls()[0].Items[0]
+			odize.AssertEqual(t, "12345678901234567890", item["id"].(*types.AttributeValueMemberN).Value)
+			odize.AssertEqual(t, "0.10000000000000000001", item["price"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should detect raw input and seed without transformation", func(t *testing.T) {
+			file := bytes.NewBufferString(`[{"pk":{"S":"pk"},"count":{"N":"10"},"tags":{"SS":["a","b"]}}]`)
+
+			err := service.Seed(ctx, "my-table", file)
+			odize.AssertNoError(t, err)
+
+			item := client.BatchPutItemsCalls()[0].Items[0]
+			odize.AssertEqual(t, "pk", item["pk"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, "10", item["count"].(*types.AttributeValueMemberN).Value)
+			odize.AssertEqual(t, []string{"a", "b"}, item["tags"].(*types.AttributeValueMemberSS).Value)
+		}).
+		Test("should seed plain json that looks like dynamodb json when raw input is false", func(t *testing.T) {
+			file := bytes.NewBufferString(`[{"pk":{"S":"pk"},"count":{"N":"10"}}]`)
+
+			err := service.Seed(ctx, "my-table", file, WithRawInput(false))
+			odize.AssertNoError(t, err)
+
+			item := client.BatchPutItemsCalls()[0].Items[0]
+			pk := item["pk"].(*types.AttributeValueMemberM).Value
+			odize.AssertEqual(t, "pk", pk["S"].(*types.AttributeValueMemberS).Value)
+			count := item["count"].(*types.AttributeValueMemberM).Value
+			odize.AssertEqual(t, "10", count["N"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should return error on invalid raw input", func(t *testing.T) {
+			file := bytes.NewBufferString(`[{"pk":"pk"}]`)
+
+			err := service.Seed(ctx, "my-table", file, WithRawInput(true))
+			odize.AssertError(t, err)
+		}).
+		Test("should return error on invalid json", func(t *testing.T) {
+			err := service.Seed(ctx, "my-table", bytes.NewBufferString(`[{"pk":`))
+			odize.AssertError(t, err)
+		}).
+		Test("should detect and seed json lines", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":\"pk-0\"}\n{\"pk\":\"pk-1\"}\n")
+
+			err := service.Seed(ctx, "my-table", file)
+			odize.AssertNoError(t, err)
+
+			items := client.BatchPutItemsCalls()[0].Items
+			odize.AssertEqual(t, 2, len(items))
+			odize.AssertEqual(t, "pk-1", items[1]["pk"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should seed raw json lines", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":{\"S\":\"pk\"},\"count\":{\"N\":\"10\"}}\n")
+
+			err := service.Seed(ctx, "my-table", file, WithInputFormat(FormatJSONL))
+			odize.AssertNoError(t, err)
+
+			item := client.BatchPutItemsCalls()[0].Items[0]
+			odize.AssertEqual(t, "10", item["count"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should seed gzip compressed input", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writer, err := NewCompressedWriter(&buf, CompressionGzip)
+			odize.AssertNoError(t, err)
+			_, _ = writer.Write(seedFile(30).Bytes())
+			odize.AssertNoError(t, writer.Close())
+
+			err = service.Seed(ctx, "my-table", &buf)
+			odize.AssertNoError(t, err)
+
+			calls := client.BatchPutItemsCalls()
+			odize.AssertEqual(t, 2, len(calls))
+			odize.AssertEqual(t, 5, len(calls[1].Items))
+		}).
+		Test("should seed csv", func(t *testing.T) {
+			file := bytes.NewBufferString("pk,count:N\npk-0,1\npk-1,2\n")
+
+			err := service.Seed(ctx, "my-table", file, WithInputFormat(FormatCSV))
+			odize.AssertNoError(t, err)
+
+			items := client.BatchPutItemsCalls()[0].Items
+			odize.AssertEqual(t, 2, len(items))
+			odize.AssertEqual(t, "2", items[1]["count"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should return error seeding parquet", func(t *testing.T) {
+			file := bytes.NewBufferString("PAR1")
+
+			err := service.Seed(ctx, "my-table", file, WithInputFormat(FormatParquet))
+			odize.AssertTrue(t, errors.Is(err, ErrUnsupportedFormat))
+			odize.AssertEqual(t, 0, len(client.BatchPutItemsCalls()))
+		}).
+		Test("should transform items before put", func(t *testing.T) {
+			transform, err := ParseTransform(strings.NewReader(`{"rules": [{"action": "rename", "attribute": "sk", "to": "sortKey"}]}`))
+			odize.AssertNoError(t, err)
+
+			err = service.Seed(ctx, "my-table", seedFile(1), WithSeedTransform(transform))
+			odize.AssertNoError(t, err)
+
+			item := client.BatchPutItemsCalls()[0].Items[0]
+			odize.AssertEqual(t, "sk", item["sortKey"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should seed nothing from an empty file", func(t *testing.T) {
+			err := service.Seed(ctx, "my-table", bytes.NewBufferString(""), WithInputFormat(FormatJSONL))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 0, len(client.BatchPutItemsCalls()))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func TestService_SeedContinueOnError(t *testing.T) {
+	var client DynamoClientMock
+	var service Service
+	var deadLetters mockDeadLetterStore
+	logger := logging.New(true)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	invalidPk := "pk-invalid"
+
+	group := odize.NewGroup(t, nil)
+
+	group.BeforeEach(func() {
+		deadLetters = mockDeadLetterStore{}
+		client = DynamoClientMock{
+			BatchPutItemsFunc: func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+				for _, item := range items {
+					if item["pk"].(*types.AttributeValueMemberS).Value == invalidPk {
+						return nil, errors.New("validation error")
+					}
+				}
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			},
+			PutFunc: func(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+				if input.Item["pk"].(*types.AttributeValueMemberS).Value == invalidPk {
+					return nil, errors.New("item too large")
+				}
+				return &dynamodb.PutItemOutput{}, nil
+			},
+			DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", ""), nil
+			},
+		}
+
+		service = Service{
+			client: &client,
+			logger: logger,
+			emitter: &mockEmitter{
+				publishFunc: func(message string) {},
+			},
+		}
+	})
+
+	err := group.
+		Test("should put the items of a failed batch individually", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":\"pk-0\"}\n{\"pk\":\"pk-invalid\"}\n{\"pk\":\"pk-2\"}\n")
+
+			err := service.Seed(ctx, "my-table", file, WithContinueOnError(true), WithDeadLetter(&deadLetters))
+
+			var seedErr *SeedError
+			odize.AssertTrue(t, errors.As(err, &seedErr))
+			odize.AssertEqual(t, 2, seedErr.Succeeded)
+			odize.AssertEqual(t, 1, seedErr.Failed)
+			odize.AssertEqual(t, 3, len(client.PutCalls()))
+
+			odize.AssertEqual(t, 1, len(deadLetters.letters))
+			odize.AssertEqual(t, 2, deadLetters.letters[0].Index)
+			odize.AssertEqual(t, "item too large", deadLetters.letters[0].Error)
+			odize.AssertEqual(t, map[string]any{"pk": map[string]any{"S": "pk-invalid"}}, deadLetters.letters[0].Item)
+		}).
+		Test("should dead letter items that cannot be read", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":{\"S\":\"pk-0\"}}\n{\"pk\":\"pk-1\"}\n")
+
+			err := service.Seed(ctx, "my-table", file, WithRawInput(true), WithContinueOnError(true), WithDeadLetter(&deadLetters))
+			odize.AssertTrue(t, errors.Is(err, ErrSeedItemsFailed))
+
+			odize.AssertEqual(t, 1, len(client.BatchPutItemsCalls()[0].Items))
+			odize.AssertEqual(t, 2, deadLetters.letters[0].Index)
+			odize.AssertEqual(t, map[string]any{"pk": "pk-1"}, deadLetters.letters[0].Item)
+		}).
+		Test("should dead letter csv rows that cannot be converted", func(t *testing.T) {
+			file := bytes.NewBufferString("pk,count:N\npk-0,1\npk-1,abc\npk-2\n")
+
+			err := service.Seed(ctx, "my-table", file, WithInputFormat(FormatCSV), WithContinueOnError(true), WithDeadLetter(&deadLetters))
+			odize.AssertTrue(t, errors.Is(err, ErrSeedItemsFailed))
+
+			odize.AssertEqual(t, 1, len(client.BatchPutItemsCalls()[0].Items))
+			odize.AssertEqual(t, 2, len(deadLetters.letters))
+			odize.AssertEqual(t, map[string]string{"pk": "pk-1", "count": "abc"}, deadLetters.letters[0].Item)
+			odize.AssertEqual(t, 3, deadLetters.letters[1].Index)
+		}).
+		Test("should stop on a failed item without continue on error", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":\"pk-invalid\"}\n")
+
+			err := service.Seed(ctx, "my-table", file, WithDeadLetter(&deadLetters))
+			odize.AssertError(t, err)
+			odize.AssertFalse(t, errors.Is(err, ErrSeedItemsFailed))
+
+			odize.AssertEqual(t, 0, len(client.PutCalls()))
+			odize.AssertEqual(t, 0, len(deadLetters.letters))
+		}).
+		Test("should stop on input that cannot be decoded", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":\"pk-0\"}\n{\"pk\":")
+
+			err := service.Seed(ctx, "my-table", file, WithContinueOnError(true), WithDeadLetter(&deadLetters))
+			odize.AssertError(t, err)
+			odize.AssertFalse(t, errors.Is(err, ErrSeedItemsFailed))
+		}).
+		Test("should not return an error when every item is seeded", func(t *testing.T) {
+			file := bytes.NewBufferString("{\"pk\":\"pk-0\"}\n")
+
+			err := service.Seed(ctx, "my-table", file, WithContinueOnError(true), WithDeadLetter(&deadLetters))
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, 0, len(deadLetters.letters))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func TestService_Copy(t *testing.T) {
+	var source DynamoClientMock
+	var target DynamoClientMock
+	var service Service
+	logger := logging.New(true)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	group := odize.NewGroup(t, nil)
+
+	group.BeforeEach(func() {
+		source = DynamoClientMock{
+			ScanFunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				items := []map[string]types.AttributeValue{}
+				for i := 0; i < 60; i++ {
+					items = append(items, map[string]types.AttributeValue{
+						"pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("pk-%d", i)},
+					})
+				}
+				return &dynamodb.ScanOutput{Items: items}, nil
+			},
+		}
+
+		target = DynamoClientMock{
+			BatchPutItemsFunc: func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			},
+			DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", "sk"), nil
+			},
+		}
+
+		service = Service{
+			client: &source,
+			dryRun: false,
+			logger: logger,
+			emitter: &mockEmitter{
+				publishFunc: func(message string) {},
+			},
+		}
+	})
+
+	err := group.
+		Test("should copy items to the target table in batches of 25", func(t *testing.T) {
+			err := service.Copy(ctx, "source-table", &target, "target-table")
+			odize.AssertNoError(t, err)
+
+			calls := target.BatchPutItemsCalls()
+			odize.AssertEqual(t, 3, len(calls))
+			odize.AssertEqual(t, "target-table", calls[0].TableName)
+			odize.AssertEqual(t, 10, len(calls[2].Items))
+			odize.AssertEqual(t, "source-table", *source.ScanCalls()[0].Input.TableName)
+		}).
+		Test("should apply filter options to the source table", func(t *testing.T) {
+			err := service.Copy(ctx, "source-table", &target, "target-table", WithFilterExpression("active = :active"), WithAttrs([]string{"pk"}))
+			odize.AssertNoError(t, err)
+
+			input := source.ScanCalls()[0].Input
+			odize.AssertEqual(t, "active = :active", *input.FilterExpression)
+			odize.AssertEqual(t, "#0", *input.ProjectionExpression)
+			odize.AssertEqual(t, map[string]string{"#0": "pk"}, input.ExpressionAttributeNames)
+		}).
+		Test("should apply the where condition to the source table", func(t *testing.T) {
+			where, err := ParseWhere(`status = "active" and created > 2024-01-01`)
+			odize.AssertNoError(t, err)
+
+			err = service.Copy(ctx, "source-table", &target, "target-table", WithWhere(where))
+			odize.AssertNoError(t, err)
+
+			input := source.ScanCalls()[0].Input
+			odize.AssertEqual(t, "(#0 = :0) AND (#1 > :1)", *input.FilterExpression)
+			odize.AssertEqual(t, map[string]string{"#0": "status", "#1": "created"}, input.ExpressionAttributeNames)
+			odize.AssertEqual(t, "2024-01-01", input.ExpressionAttributeValues[":1"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should start a new batch when a transform gives an item a key already in the batch", func(t *testing.T) {
+			target.DescribeTableFunc = func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", ""), nil
+			}
+			transform, err := ParseTransform(strings.NewReader(`{"rules": [{"action": "replace", "attribute": "pk", "pattern": "^pk-1$", "replacement": "pk-0"}]}`))
+			odize.AssertNoError(t, err)
+
+			err = service.Copy(ctx, "source-table", &target, "target-table", WithTransform(transform))
+			odize.AssertNoError(t, err)
+
+			calls := target.BatchPutItemsCalls()
+			odize.AssertEqual(t, 4, len(calls))
+			odize.AssertEqual(t, 1, len(calls[0].Items))
+			odize.AssertEqual(t, 25, len(calls[1].Items))
+			odize.AssertEqual(t, "pk-0", calls[1].Items[0]["pk"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, 9, len(calls[3].Items))
+			odize.AssertEqual(t, "target-table", *target.DescribeTableCalls()[0].Input.TableName)
+		}).
+		Test("should not write items on dry run", func(t *testing.T) {
+			service.dryRun = true
+
+			err := service.Copy(ctx, "source-table", &target, "target-table")
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 0, len(target.BatchPutItemsCalls()))
+		}).
+		Test("should return error if batch put fails", func(t *testing.T) {
+			expectedErr := errors.New("batch put failed")
+			target.BatchPutItemsFunc = func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+				return nil, expectedErr
+			}
+
+			err := service.Copy(ctx, "source-table", &target, "target-table")
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
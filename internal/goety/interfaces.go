@@ -13,7 +13,10 @@ import (
 type DynamoClient interface {
 	Put(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
 	Scan(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+	Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	DescribeTable(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error)
 	BatchDeleteItems(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error)
+	BatchPutItems(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error)
 }
 
 var _ DynamoClient = (*ddb.Client)(nil)
@@ -23,6 +26,17 @@ type Writer interface {
 	io.StringWriter
 }
 
+type CheckpointStore interface {
+	Load() (*Checkpoint, error)
+	Save(checkpoint *Checkpoint) error
+}
+
+type DeadLetterStore interface {
+	Add(letter DeadLetter) error
+}
+
+var _ CheckpointStore = (*FileCheckpoint)(nil)
+
 type Emitter interface {
 	Publish(msg string)
 }
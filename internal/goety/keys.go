@@ -0,0 +1,190 @@
+package goety
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	ErrTableNotDescribed = errors.New("could not describe table")
+	ErrTableKeyMismatch  = errors.New("table keys do not match the table key schema")
+)
+
+// resolveTableKeys - resolves the table keys and their types from the table key schema.
+// Keys that are provided act as overrides and must match the key schema.
+func (s Service) resolveTableKeys(ctx context.Context, tableName string, keys TableKeys) (TableKeys, error) {
+	output, err := s.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: &tableName,
+	})
+	if err != nil {
+		s.logger.Error("could not describe table", "error", err)
+		return keys, err
+	}
+
+	if output == nil || output.Table == nil {
+		return keys, fmt.Errorf("%w: %s", ErrTableNotDescribed, tableName)
+	}
+
+	schema := TableKeys{}
+	for _, element := range output.Table.KeySchema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			schema.PartitionKey = *element.AttributeName
+		case types.KeyTypeRange:
+			schema.SortKey = *element.AttributeName
+		}
+	}
+
+	if keys.PartitionKey != "" && keys.PartitionKey != schema.PartitionKey {
+		return keys, fmt.Errorf("%w: partition key %q, table partition key %q", ErrTableKeyMismatch, keys.PartitionKey, schema.PartitionKey)
+	}
+
+	if keys.SortKey != "" && keys.SortKey != schema.SortKey {
+		return keys, fmt.Errorf("%w: sort key %q, table sort key %q", ErrTableKeyMismatch, keys.SortKey, schema.SortKey)
+	}
+
+	for _, definition := range output.Table.AttributeDefinitions {
+		switch *definition.AttributeName {
+		case schema.PartitionKey:
+			schema.partitionType = definition.AttributeType
+		case schema.SortKey:
+			schema.sortType = definition.AttributeType
+		}
+	}
+
+	s.logger.Debug("resolved table keys", "partitionKey", schema.PartitionKey, "sortKey", schema.SortKey)
+
+	return schema, nil
+}
+
+// projection - returns a projection expression of the key attributes, adding an alias for each key to the attribute names.
+// Aliasing avoids reserved words and, leaves any names used by a filter expression untouched.
+func (k TableKeys) projection(names map[string]string) string {
+	aliases := []string{}
+	for i, attribute := range k.attributes() {
+		alias := fmt.Sprintf("#goetyKey%d", i)
+		names[alias] = attribute
+		aliases = append(aliases, alias)
+	}
+
+	return strings.Join(aliases, ", ")
+}
+
+// attributes - returns the names of the key attributes, omitting the sort key for hash only tables
+func (k TableKeys) attributes() []string {
+	if k.SortKey == "" {
+		return []string{k.PartitionKey}
+	}
+
+	return []string{k.PartitionKey, k.SortKey}
+}
+
+// batchTableKeys - resolves the table keys used to find items that repeat a key in a batch.
+// When the caller is not permitted to describe the table the keys are unknown, and batches are not split.
+func (s Service) batchTableKeys(ctx context.Context, tableName string) (TableKeys, error) {
+	keys, err := s.resolveTableKeys(ctx, tableName, TableKeys{})
+	if isAccessDenied(err) {
+		s.logger.Warn("not permitted to describe table, a batch with an item that repeats a key in it will fail", "table", tableName)
+		return TableKeys{}, nil
+	}
+
+	return keys, err
+}
+
+// isAccessDenied - reports whether a request failed because the caller is not permitted to make it
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDeniedException"
+}
+
+// itemKey - returns the key attribute values of an item as a string, items with the same key have the same string.
+// Numbers are normalised so equal numbers written differently, such as 1 and 1.0, have the same string.
+// Returns an empty string when the keys are unknown.
+func (k TableKeys) itemKey(item map[string]types.AttributeValue) string {
+	if k.PartitionKey == "" {
+		return ""
+	}
+
+	parts := []string{}
+	for _, attribute := range k.attributes() {
+		switch v := item[attribute].(type) {
+		case *types.AttributeValueMemberS:
+			parts = append(parts, "S:"+strconv.Quote(v.Value))
+		case *types.AttributeValueMemberN:
+			parts = append(parts, "N:"+normaliseNumber(v.Value))
+		case *types.AttributeValueMemberB:
+			parts = append(parts, "B:"+base64.StdEncoding.EncodeToString(v.Value))
+		default:
+			parts = append(parts, "")
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// batchKeys - tracks the keys of the items in a batch, a batch write cannot put two items with the same key
+type batchKeys struct {
+	keys TableKeys
+	seen map[string]struct{}
+}
+
+// newBatchKeys - creates an empty set of batch keys for the table keys
+func newBatchKeys(keys TableKeys) *batchKeys {
+	return &batchKeys{
+		keys: keys,
+		seen: map[string]struct{}{},
+	}
+}
+
+// add - adds the item's key to the batch, returns false when the item repeats a key already in the batch.
+// Items always join the batch when the table keys are unknown.
+func (b *batchKeys) add(item map[string]types.AttributeValue) bool {
+	key := b.keys.itemKey(item)
+	if key == "" {
+		return true
+	}
+
+	if _, ok := b.seen[key]; ok {
+		return false
+	}
+
+	b.seen[key] = struct{}{}
+	return true
+}
+
+// reset - empties the batch keys for a new batch
+func (b *batchKeys) reset() {
+	clear(b.seen)
+}
+
+// batches - splits items into batches of up to defaultBatchSize, a new batch starts before an item that repeats a key in the current batch
+func (b *batchKeys) batches(items []map[string]types.AttributeValue) [][]map[string]types.AttributeValue {
+	batches := [][]map[string]types.AttributeValue{}
+	batch := []map[string]types.AttributeValue{}
+	b.reset()
+
+	for _, item := range items {
+		if len(batch) == defaultBatchSize || !b.add(item) {
+			batches = append(batches, batch)
+			batch = []map[string]types.AttributeValue{}
+			b.reset()
+			b.add(item)
+		}
+
+		batch = append(batch, item)
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
@@ -0,0 +1,242 @@
+package goety
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidMask     = errors.New("invalid mask")
+	ErrUnsupportedMask = errors.New("mask strategy does not support the attribute type")
+)
+
+// MaskStrategy - how an attribute is masked
+type MaskStrategy string
+
+const (
+	// MaskRedact - replaces strings with the replacement, numbers with zero and, any other value with null
+	MaskRedact MaskStrategy = "redact"
+	// MaskHash - replaces strings with the salted sha256 hex hash, numbers with a number derived from the hash and, binary with the hash bytes.
+	// The same value is always masked the same way, so masked keys and references still match.
+	MaskHash MaskStrategy = "hash"
+	// MaskFakeEmail - replaces strings with a fake email address derived from the hash of the value
+	MaskFakeEmail MaskStrategy = "fake_email"
+	// MaskFakeName - replaces strings with a fake name derived from the hash of the value
+	MaskFakeName MaskStrategy = "fake_name"
+	// MaskTruncate - truncates strings to the length
+	MaskTruncate MaskStrategy = "truncate"
+)
+
+const defaultRedaction = "REDACTED"
+
+var (
+	fakeFirstNames = []string{"Alex", "Bailey", "Casey", "Drew", "Emerson", "Finley", "Harper", "Jordan", "Kai", "Morgan", "Quinn", "Riley", "Rowan", "Sage", "Taylor", "Avery"}
+	fakeLastNames  = []string{"Ashby", "Brooks", "Carter", "Dalton", "Ellis", "Fletcher", "Grant", "Hayes", "Irving", "Keller", "Lawson", "Mercer", "Nolan", "Parker", "Reed", "Sutton"}
+)
+
+// Mask - the masking strategy for an attribute.
+// The attribute is an attribute name, or a dotted path to an attribute of a nested map e.g. "address.street".
+// String sets are masked element by element.
+type Mask struct {
+	Attribute   string       `yaml:"attribute"`
+	Strategy    MaskStrategy `yaml:"strategy"`
+	Replacement string       `yaml:"replacement,omitempty"`
+	Length      int          `yaml:"length,omitempty"`
+}
+
+// MaskConfig - masks applied to each dumped item, the salt keeps hashed values from being reversed with a lookup table
+type MaskConfig struct {
+	Salt  string `yaml:"salt"`
+	Masks []Mask `yaml:"masks"`
+}
+
+// LoadMaskConfig - loads masks from a yaml file
+//
+// Example:
+//
+//	salt: contractors
+//	masks:
+//	  - attribute: email
+//	    strategy: fake_email
+//	  - attribute: address.street
+//	    strategy: redact
+//	  - attribute: notes
+//	    strategy: truncate
+//	    length: 10
+func LoadMaskConfig(path string) (*MaskConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseMaskConfig(file)
+}
+
+// ParseMaskConfig - parses and validates masks from yaml
+func ParseMaskConfig(reader io.Reader) (*MaskConfig, error) {
+	var config MaskConfig
+
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	for i, mask := range config.Masks {
+		if err := mask.validate(); err != nil {
+			return nil, fmt.Errorf("mask %d: %w", i+1, err)
+		}
+	}
+
+	return &config, nil
+}
+
+// validate - ensures the mask has an attribute and, the options its strategy requires
+func (m Mask) validate() error {
+	if m.Attribute == "" {
+		return fmt.Errorf("%w: attribute is required", ErrInvalidMask)
+	}
+
+	switch m.Strategy {
+	case MaskRedact, MaskHash, MaskFakeEmail, MaskFakeName:
+	case MaskTruncate:
+		if m.Length < 1 {
+			return fmt.Errorf("%w: truncate requires a length of at least 1", ErrInvalidMask)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported strategy %q", ErrInvalidMask, m.Strategy)
+	}
+
+	return nil
+}
+
+// Apply - masks the attributes of the item in place, attributes the item does not have are skipped
+func (c *MaskConfig) Apply(item map[string]types.AttributeValue) error {
+	if c == nil {
+		return nil
+	}
+
+	for _, mask := range c.Masks {
+		value, ok := lookupAttribute(item, mask.Attribute)
+		if !ok {
+			continue
+		}
+
+		masked, err := c.mask(mask, value)
+		if err != nil {
+			return fmt.Errorf("attribute %s: %w", mask.Attribute, err)
+		}
+
+		setAttribute(item, mask.Attribute, masked)
+	}
+
+	return nil
+}
+
+// ApplyAll - masks each item
+func (c *MaskConfig) ApplyAll(items []map[string]types.AttributeValue) error {
+	for _, item := range items {
+		if err := c.Apply(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mask - masks a value, keeping the attribute type so the masked item can be seeded
+func (c *MaskConfig) mask(mask Mask, value types.AttributeValue) (types.AttributeValue, error) {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		masked, err := c.maskString(mask, v.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberS{Value: masked}, nil
+	case *types.AttributeValueMemberSS:
+		// members can mask to the same value e.g. redact, sets cannot hold duplicates so only the first is kept
+		masked := make([]string, 0, len(v.Value))
+		for _, element := range v.Value {
+			member, err := c.maskString(mask, element)
+			if err != nil {
+				return nil, err
+			}
+			if !slices.Contains(masked, member) {
+				masked = append(masked, member)
+			}
+		}
+		return &types.AttributeValueMemberSS{Value: masked}, nil
+	case *types.AttributeValueMemberN:
+		switch mask.Strategy {
+		case MaskRedact:
+			return &types.AttributeValueMemberN{Value: "0"}, nil
+		case MaskHash:
+			return &types.AttributeValueMemberN{Value: c.hashNumber(v.Value)}, nil
+		}
+	case *types.AttributeValueMemberB:
+		switch mask.Strategy {
+		case MaskRedact:
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		case MaskHash:
+			sum := c.hash(string(v.Value))
+			return &types.AttributeValueMemberB{Value: sum[:]}, nil
+		}
+	default:
+		if mask.Strategy == MaskRedact {
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedMask, mask.Strategy)
+}
+
+// maskString - masks a string with the strategy
+func (c *MaskConfig) maskString(mask Mask, value string) (string, error) {
+	switch mask.Strategy {
+	case MaskRedact:
+		if mask.Replacement != "" {
+			return mask.Replacement, nil
+		}
+		return defaultRedaction, nil
+	case MaskHash:
+		sum := c.hash(value)
+		return hex.EncodeToString(sum[:]), nil
+	case MaskFakeEmail:
+		sum := c.hash(value)
+		return fmt.Sprintf("user-%s@example.com", hex.EncodeToString(sum[:6])), nil
+	case MaskFakeName:
+		sum := c.hash(value)
+		return fmt.Sprintf("%s %s", fakeFirstNames[int(sum[0])%len(fakeFirstNames)], fakeLastNames[int(sum[1])%len(fakeLastNames)]), nil
+	case MaskTruncate:
+		runes := []rune(value)
+		if len(runes) <= mask.Length {
+			return value, nil
+		}
+		return string(runes[:mask.Length]), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedMask, mask.Strategy)
+}
+
+// hash - returns the salted sha256 hash of the value
+func (c *MaskConfig) hash(value string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(c.Salt + value))
+}
+
+// hashNumber - returns a non negative number derived from the hash of the numeric text, it fits within an int64
+func (c *MaskConfig) hashNumber(value string) string {
+	sum := c.hash(value)
+	return strconv.FormatUint(binary.BigEndian.Uint64(sum[:8])>>1, 10)
+}
@@ -0,0 +1,142 @@
+package goety
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestMaskConfig(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var item map[string]types.AttributeValue
+
+	group.BeforeEach(func() {
+		item = map[string]types.AttributeValue{
+			"pk":    &types.AttributeValueMemberS{Value: "user-1"},
+			"email": &types.AttributeValueMemberS{Value: "person@example.com"},
+			"name":  &types.AttributeValueMemberS{Value: "Jane Citizen"},
+			"notes": &types.AttributeValueMemberS{Value: "called about invoice"},
+			"phone": &types.AttributeValueMemberN{Value: "61400000000"},
+			"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"street": &types.AttributeValueMemberS{Value: "1 Queen St"},
+				"city":   &types.AttributeValueMemberS{Value: "Brisbane"},
+			}},
+		}
+	})
+
+	parse := func(t *testing.T, config string) *MaskConfig {
+		mask, err := ParseMaskConfig(strings.NewReader(config))
+		odize.AssertNoError(t, err)
+		return mask
+	}
+
+	err := group.
+		Test("should mask attributes keeping their type", func(t *testing.T) {
+			mask := parse(t, `
+salt: contractors
+masks:
+  - attribute: email
+    strategy: fake_email
+  - attribute: name
+    strategy: fake_name
+  - attribute: notes
+    strategy: truncate
+    length: 6
+  - attribute: phone
+    strategy: hash
+  - attribute: address.street
+    strategy: redact
+`)
+
+			odize.AssertNoError(t, mask.Apply(item))
+
+			email := item["email"].(*types.AttributeValueMemberS).Value
+			odize.AssertTrue(t, strings.HasPrefix(email, "user-"))
+			odize.AssertTrue(t, strings.HasSuffix(email, "@example.com"))
+			odize.AssertFalse(t, strings.Contains(email, "person"))
+
+			odize.AssertEqual(t, 2, len(strings.Fields(item["name"].(*types.AttributeValueMemberS).Value)))
+			odize.AssertEqual(t, "called", item["notes"].(*types.AttributeValueMemberS).Value)
+
+			phone := item["phone"].(*types.AttributeValueMemberN).Value
+			odize.AssertFalse(t, phone == "61400000000")
+
+			address := item["address"].(*types.AttributeValueMemberM).Value
+			odize.AssertEqual(t, "REDACTED", address["street"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, "Brisbane", address["city"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, "user-1", item["pk"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should mask the same value the same way", func(t *testing.T) {
+			mask := parse(t, "salt: contractors\nmasks:\n  - attribute: pk\n    strategy: hash\n")
+
+			other := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "user-1"}}
+			odize.AssertNoError(t, mask.Apply(item))
+			odize.AssertNoError(t, mask.Apply(other))
+
+			odize.AssertEqual(t, item["pk"], other["pk"])
+		}).
+		Test("should return error on unsupported attribute type", func(t *testing.T) {
+			mask := parse(t, "masks:\n  - attribute: phone\n    strategy: fake_email\n")
+
+			err := mask.Apply(item)
+			odize.AssertTrue(t, errors.Is(err, ErrUnsupportedMask))
+		}).
+		Test("should return error on invalid masks", func(t *testing.T) {
+			_, err := ParseMaskConfig(strings.NewReader("masks:\n  - attribute: notes\n    strategy: truncate\n"))
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidMask))
+
+			_, err = ParseMaskConfig(strings.NewReader("masks:\n  - attribute: notes\n    strategy: shuffle\n"))
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidMask))
+		}).
+		Test("should keep masked set members unique so the dump can be reseeded", func(t *testing.T) {
+			mask := parse(t, "masks:\n  - attribute: tags\n    strategy: redact\n")
+			logger := logging.New(true)
+			ctx := logging.WithContext(context.Background(), logger)
+
+			var seeded []map[string]types.AttributeValue
+			client := DynamoClientMock{
+				ScanFunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+					return &dynamodb.ScanOutput{
+						Items: []map[string]types.AttributeValue{
+							{
+								"pk":   &types.AttributeValueMemberS{Value: "pk"},
+								"tags": &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+							},
+						},
+					}, nil
+				},
+				BatchPutItemsFunc: func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+					seeded = append(seeded, items...)
+					return &dynamodb.BatchWriteItemOutput{}, nil
+				},
+				DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+					return describeTableOutput("pk", ""), nil
+				},
+			}
+			service := Service{
+				client: &client,
+				logger: logger,
+				emitter: &mockEmitter{
+					publishFunc: func(message string) {},
+				},
+			}
+
+			dump := bytes.Buffer{}
+			odize.AssertNoError(t, service.Dump(ctx, "my-table", &dump, WithRawOutput(true), WithMask(mask)))
+			odize.AssertNoError(t, service.Seed(ctx, "my-table", &dump, WithRawInput(true)))
+
+			odize.AssertEqual(t, 1, len(seeded))
+			odize.AssertEqual(t, []string{"REDACTED"}, seeded[0]["tags"].(*types.AttributeValueMemberSS).Value)
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
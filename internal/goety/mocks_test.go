@@ -23,9 +23,18 @@ var _ DynamoClient = &DynamoClientMock{}
 //			BatchDeleteItemsFunc: func(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
 //				panic("mock out the BatchDeleteItems method")
 //			},
+//			BatchPutItemsFunc: func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+//				panic("mock out the BatchPutItems method")
+//			},
+//			DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+//				panic("mock out the DescribeTable method")
+//			},
 //			PutFunc: func(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
 //				panic("mock out the Put method")
 //			},
+//			QueryFunc: func(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+//				panic("mock out the Query method")
+//			},
 //			ScanFunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 //				panic("mock out the Scan method")
 //			},
@@ -39,9 +48,18 @@ type DynamoClientMock struct {
 	// BatchDeleteItemsFunc mocks the BatchDeleteItems method.
 	BatchDeleteItemsFunc func(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error)
 
+	// BatchPutItemsFunc mocks the BatchPutItems method.
+	BatchPutItemsFunc func(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error)
+
+	// DescribeTableFunc mocks the DescribeTable method.
+	DescribeTableFunc func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error)
+
 	// PutFunc mocks the Put method.
 	PutFunc func(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
 
+	// QueryFunc mocks the Query method.
+	QueryFunc func(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+
 	// ScanFunc mocks the Scan method.
 	ScanFunc func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
 
@@ -56,6 +74,22 @@ type DynamoClientMock struct {
 			// Keys is the keys argument value.
 			Keys []map[string]types.AttributeValue
 		}
+		// BatchPutItems holds details about calls to the BatchPutItems method.
+		BatchPutItems []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// TableName is the tableName argument value.
+			TableName string
+			// Items is the items argument value.
+			Items []map[string]types.AttributeValue
+		}
+		// DescribeTable holds details about calls to the DescribeTable method.
+		DescribeTable []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *dynamodb.DescribeTableInput
+		}
 		// Put holds details about calls to the Put method.
 		Put []struct {
 			// Ctx is the ctx argument value.
@@ -63,6 +97,13 @@ type DynamoClientMock struct {
 			// Input is the input argument value.
 			Input *dynamodb.PutItemInput
 		}
+		// Query holds details about calls to the Query method.
+		Query []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Input is the input argument value.
+			Input *dynamodb.QueryInput
+		}
 		// Scan holds details about calls to the Scan method.
 		Scan []struct {
 			// Ctx is the ctx argument value.
@@ -72,7 +113,10 @@ type DynamoClientMock struct {
 		}
 	}
 	lockBatchDeleteItems sync.RWMutex
+	lockBatchPutItems    sync.RWMutex
+	lockDescribeTable    sync.RWMutex
 	lockPut              sync.RWMutex
+	lockQuery            sync.RWMutex
 	lockScan             sync.RWMutex
 }
 
@@ -120,6 +164,90 @@ func (mock *DynamoClientMock) BatchDeleteItemsCalls() []struct {
 	return calls
 }
 
+// BatchPutItems calls BatchPutItemsFunc.
+func (mock *DynamoClientMock) BatchPutItems(ctx context.Context, tableName string, items []map[string]types.AttributeValue) (*dynamodb.BatchWriteItemOutput, error) {
+	callInfo := struct {
+		Ctx       context.Context
+		TableName string
+		Items     []map[string]types.AttributeValue
+	}{
+		Ctx:       ctx,
+		TableName: tableName,
+		Items:     items,
+	}
+	mock.lockBatchPutItems.Lock()
+	mock.calls.BatchPutItems = append(mock.calls.BatchPutItems, callInfo)
+	mock.lockBatchPutItems.Unlock()
+	if mock.BatchPutItemsFunc == nil {
+		var (
+			batchWriteItemOutputOut *dynamodb.BatchWriteItemOutput
+			errOut                  error
+		)
+		return batchWriteItemOutputOut, errOut
+	}
+	return mock.BatchPutItemsFunc(ctx, tableName, items)
+}
+
+// BatchPutItemsCalls gets all the calls that were made to BatchPutItems.
+// Check the length with:
+//
+//	len(mockedDynamoClient.BatchPutItemsCalls())
+func (mock *DynamoClientMock) BatchPutItemsCalls() []struct {
+	Ctx       context.Context
+	TableName string
+	Items     []map[string]types.AttributeValue
+} {
+	var calls []struct {
+		Ctx       context.Context
+		TableName string
+		Items     []map[string]types.AttributeValue
+	}
+	mock.lockBatchPutItems.RLock()
+	calls = mock.calls.BatchPutItems
+	mock.lockBatchPutItems.RUnlock()
+	return calls
+}
+
+// DescribeTable calls DescribeTableFunc.
+func (mock *DynamoClientMock) DescribeTable(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	callInfo := struct {
+		Ctx   context.Context
+		Input *dynamodb.DescribeTableInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockDescribeTable.Lock()
+	mock.calls.DescribeTable = append(mock.calls.DescribeTable, callInfo)
+	mock.lockDescribeTable.Unlock()
+	if mock.DescribeTableFunc == nil {
+		var (
+			describeTableOutputOut *dynamodb.DescribeTableOutput
+			errOut                 error
+		)
+		return describeTableOutputOut, errOut
+	}
+	return mock.DescribeTableFunc(ctx, input)
+}
+
+// DescribeTableCalls gets all the calls that were made to DescribeTable.
+// Check the length with:
+//
+//	len(mockedDynamoClient.DescribeTableCalls())
+func (mock *DynamoClientMock) DescribeTableCalls() []struct {
+	Ctx   context.Context
+	Input *dynamodb.DescribeTableInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *dynamodb.DescribeTableInput
+	}
+	mock.lockDescribeTable.RLock()
+	calls = mock.calls.DescribeTable
+	mock.lockDescribeTable.RUnlock()
+	return calls
+}
+
 // Put calls PutFunc.
 func (mock *DynamoClientMock) Put(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
 	callInfo := struct {
@@ -160,6 +288,46 @@ func (mock *DynamoClientMock) PutCalls() []struct {
 	return calls
 }
 
+// Query calls QueryFunc.
+func (mock *DynamoClientMock) Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	callInfo := struct {
+		Ctx   context.Context
+		Input *dynamodb.QueryInput
+	}{
+		Ctx:   ctx,
+		Input: input,
+	}
+	mock.lockQuery.Lock()
+	mock.calls.Query = append(mock.calls.Query, callInfo)
+	mock.lockQuery.Unlock()
+	if mock.QueryFunc == nil {
+		var (
+			queryOutputOut *dynamodb.QueryOutput
+			errOut         error
+		)
+		return queryOutputOut, errOut
+	}
+	return mock.QueryFunc(ctx, input)
+}
+
+// QueryCalls gets all the calls that were made to Query.
+// Check the length with:
+//
+//	len(mockedDynamoClient.QueryCalls())
+func (mock *DynamoClientMock) QueryCalls() []struct {
+	Ctx   context.Context
+	Input *dynamodb.QueryInput
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Input *dynamodb.QueryInput
+	}
+	mock.lockQuery.RLock()
+	calls = mock.calls.Query
+	mock.lockQuery.RUnlock()
+	return calls
+}
+
 // Scan calls ScanFunc.
 func (mock *DynamoClientMock) Scan(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 	callInfo := struct {
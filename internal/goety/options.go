@@ -1,14 +1,18 @@
 package goety
 
 import (
+	"maps"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
 )
 
 func WithQueryOptions(opts []QueryFuncOpts) *QueryOpts {
-	queryOpts := &QueryOpts{}
+	queryOpts := &QueryOpts{
+		NumberMode: ddb.NumberModeExact,
+	}
 
 	for _, opt := range opts {
 		queryOpts = opt(queryOpts)
@@ -25,6 +29,31 @@ func WithAttrs(attrs []string) QueryFuncOpts {
 		}
 
 		opts.ProjectedExpressions = aws.String(strings.Join(attrs, ", "))
+		opts.Attributes = attrs
+		return opts
+	}
+}
+
+// WithKeyCondition - provide a key condition, the table will be queried instead of scanned
+func WithKeyCondition(condition string) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		if condition == "" {
+			return opts
+		}
+
+		opts.KeyConditionExpression = aws.String(condition)
+		return opts
+	}
+}
+
+// WithIndex - provide a global or local secondary index to read from instead of the base table
+func WithIndex(indexName string) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		if indexName == "" {
+			return opts
+		}
+
+		opts.IndexName = aws.String(indexName)
 		return opts
 	}
 }
@@ -66,27 +95,47 @@ func WithFilterNameAttrs(attrName string) QueryFuncOpts {
 	}
 }
 
-// WithFilterNameValues - provide a list of attribute values to filter on
+// WithFilterNameValues - provide a list of attribute values to filter on, in the form ":name=TYPE:value".
+// Malformed values are skipped, use ParseFilterValues with WithFilterValues to report them.
 func WithFilterNameValues(attrValues string) QueryFuncOpts {
 	return func(opts *QueryOpts) *QueryOpts {
-		if attrValues == "" {
+		if strings.TrimSpace(attrValues) == "" {
 			return opts
 		}
 
-		filterNameValues := make(map[string]types.AttributeValue)
-		expList := strings.Split(attrValues, ",")
-
-		for _, exp := range expList {
-			ex := strings.Split(exp, "=")
-			if len(ex) < 2 {
+		values := map[string]types.AttributeValue{}
+		for _, entry := range splitEscaped(attrValues, ',') {
+			name, value, err := parseFilterEntry(entry)
+			if err != nil {
 				continue
 			}
-			tKey := strings.TrimSpace(ex[0])
-			tVal := types.AttributeValueMemberS{Value: strings.TrimSpace(ex[1])}
-			filterNameValues[tKey] = &tVal
+			values[name] = value
+		}
+
+		return WithFilterValues(values)(opts)
+	}
+}
+
+// WithFilterValues - provide typed attribute values to filter on, merged with any values already provided
+func WithFilterValues(values map[string]types.AttributeValue) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		if len(values) == 0 {
+			return opts
+		}
+
+		if opts.FilterNameValues == nil {
+			opts.FilterNameValues = map[string]types.AttributeValue{}
 		}
 
-		opts.FilterNameValues = filterNameValues
+		maps.Copy(opts.FilterNameValues, values)
+		return opts
+	}
+}
+
+// WithWhere - provide a where condition, combined with any filter expression
+func WithWhere(where *Where) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.Where = where
 		return opts
 	}
 }
@@ -109,3 +158,183 @@ func WithRawOutput(raw bool) QueryFuncOpts {
 		return opts
 	}
 }
+
+// WithSegments - provide the number of segments to scan the table with in parallel
+func WithSegments(segments int32) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		if segments <= 1 {
+			return opts
+		}
+
+		opts.Segments = segments
+		return opts
+	}
+}
+
+// WithNumberMode - provide how numbers are written when flattening items, defaults to exact
+func WithNumberMode(mode ddb.NumberMode) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		if mode == "" {
+			return opts
+		}
+
+		opts.NumberMode = mode
+		return opts
+	}
+}
+
+// WithCheckpoint - provide a store to save progress to after each page of items
+func WithCheckpoint(store CheckpointStore) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.Checkpoint = store
+		return opts
+	}
+}
+
+// WithResume - resume from the last saved checkpoint, requires a checkpoint store
+func WithResume(resume bool) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.Resume = resume
+		return opts
+	}
+}
+
+// WithFormat - provide the file format items are dumped to, defaults to a json array
+func WithFormat(format Format) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.Format = format
+		return opts
+	}
+}
+
+// WithCSVNested - provide how nested values are written to csv, defaults to a json cell
+func WithCSVNested(nested CSVNested) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.CSVNested = nested
+		return opts
+	}
+}
+
+// WithTransform - provide transform rules applied to each item read from the table
+func WithTransform(transform *Transform) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.Transform = transform
+		return opts
+	}
+}
+
+// WithMask - provide masks applied to each dumped item
+func WithMask(mask *MaskConfig) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.Mask = mask
+		return opts
+	}
+}
+
+// WithPartition - only read the items of the partition, the partition key is queried instead of scanning the table
+func WithPartition(partition string) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		if partition == "" {
+			return opts
+		}
+
+		opts.Partition = &partition
+		return opts
+	}
+}
+
+// WithSortCondition - narrow the items of a partition with a condition on the sort key
+func WithSortCondition(condition *SortCondition) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.SortCondition = condition
+		return opts
+	}
+}
+
+func WithSeedOptions(opts []SeedFuncOpts) *SeedOpts {
+	seedOpts := &SeedOpts{}
+
+	for _, opt := range opts {
+		seedOpts = opt(seedOpts)
+	}
+
+	return seedOpts
+}
+
+// WithRawInput - treat the seed file as dynamodb json when true, as written by a raw output dump, or as plain json when false.
+// When not set, the format is detected from the first item in the file.
+func WithRawInput(raw bool) SeedFuncOpts {
+	return func(opts *SeedOpts) *SeedOpts {
+		opts.RawInput = &raw
+		return opts
+	}
+}
+
+// WithInputFormat - provide the file format items are seeded from.
+// When not set, the format is detected from the first character of the file.
+func WithInputFormat(format Format) SeedFuncOpts {
+	return func(opts *SeedOpts) *SeedOpts {
+		opts.Format = format
+		return opts
+	}
+}
+
+// WithColumnTypes - provide attribute types for csv columns, overriding type hints in the header
+func WithColumnTypes(columnTypes map[string]string) SeedFuncOpts {
+	return func(opts *SeedOpts) *SeedOpts {
+		opts.ColumnTypes = columnTypes
+		return opts
+	}
+}
+
+// WithSeedTransform - provide transform rules applied to each item before it is put to the table
+func WithSeedTransform(transform *Transform) SeedFuncOpts {
+	return func(opts *SeedOpts) *SeedOpts {
+		opts.Transform = transform
+		return opts
+	}
+}
+
+// WithContinueOnError - continue seeding when an item fails, failed items are added to the dead letter store
+func WithContinueOnError(continueOnError bool) SeedFuncOpts {
+	return func(opts *SeedOpts) *SeedOpts {
+		opts.ContinueOnError = continueOnError
+		return opts
+	}
+}
+
+// WithDeadLetter - provide a store for the items that fail when continuing on error
+func WithDeadLetter(store DeadLetterStore) SeedFuncOpts {
+	return func(opts *SeedOpts) *SeedOpts {
+		opts.DeadLetter = store
+		return opts
+	}
+}
+
+// WithSample - provide the percentage of the table to scan when profiling, the whole table is scanned when not set
+func WithSample(percent float64) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.Sample = percent
+		return opts
+	}
+}
+
+// WithTopPartitions - provide the number of hottest partitions reported when profiling
+func WithTopPartitions(top int) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		if top <= 0 {
+			return opts
+		}
+
+		opts.TopPartitions = top
+		return opts
+	}
+}
+
+// WithEntityAttribute - provide the attribute that discriminates the entity type of an item when inferring a schema
+func WithEntityAttribute(attribute string) QueryFuncOpts {
+	return func(opts *QueryOpts) *QueryOpts {
+		opts.EntityAttribute = attribute
+		return opts
+	}
+}
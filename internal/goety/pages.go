@@ -0,0 +1,207 @@
+package goety
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+)
+
+// scanNext - iterator function returned by the dynamodb scan iterators
+type scanNext = func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error, bool)
+
+// scanSegments - runs the scan function once per segment, in parallel when more than one segment is requested.
+// Returns the first error encountered, cancelling the remaining segments.
+func (s Service) scanSegments(ctx context.Context, segments int32, scanFn segmentFunc) error {
+	if segments <= 1 {
+		return scanFn(ctx, 0, ddb.ScanIterator(ctx, s.client))
+	}
+
+	all := make([]int32, 0, segments)
+	for segment := range segments {
+		all = append(all, segment)
+	}
+
+	s.logger.Debug("starting parallel scan", "segments", segments)
+	return s.scanSegmentSubset(ctx, all, segments, int(segments), scanFn)
+}
+
+// segmentFunc - scans a single segment of the table with the given iterator
+type segmentFunc = func(ctx context.Context, segment int32, next scanNext) error
+
+// scanSegmentSubset - runs the scan function for each of the given segments of a scan divided into totalSegments, with up to parallel segments scanned at once.
+// Returns the first error encountered, cancelling the remaining segments, or the context's error when it is cancelled before every segment is scanned.
+func (s Service) scanSegmentSubset(ctx context.Context, segments []int32, totalSegments int32, parallel int, scanFn segmentFunc) error {
+	scanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(segments))
+	limit := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+
+	for _, segment := range segments {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case limit <- struct{}{}:
+				defer func() { <-limit }()
+			case <-scanCtx.Done():
+				return
+			}
+
+			next := ddb.SegmentScanIterator(scanCtx, s.client, segment, totalSegments)
+			if err := scanFn(scanCtx, segment, next); err != nil {
+				// segments stopped by a cancelled scan are not failures of their own
+				if scanCtx.Err() == nil {
+					s.logger.Error("could not scan segment", "segment", segment, "error", err)
+				}
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// page - a single page of items read from a scan or query
+type page struct {
+	Items            []map[string]types.AttributeValue
+	LastEvaluatedKey map[string]types.AttributeValue
+}
+
+// pageFunc - handles a page of items read from the given segment
+type pageFunc = func(ctx context.Context, segment int32, p page) error
+
+// scanPages - scans the table across the given number of segments, calling pageFn for each page of items.
+// newInput is called for every request so each segment can safely modify its own input.
+// When resuming from a checkpoint, each segment continues from its last evaluated key.
+func (s Service) scanPages(ctx context.Context, segments int32, resume *Checkpoint, newInput func() *dynamodb.ScanInput, pageFn pageFunc) error {
+	return s.scanSegments(ctx, segments, s.segmentPages(resume, newInput, pageFn))
+}
+
+// segmentPages - returns a segment function that reads every page of its segment, calling pageFn for each page of items.
+// Stops with the context's error when the context is cancelled between pages.
+func (s Service) segmentPages(resume *Checkpoint, newInput func() *dynamodb.ScanInput, pageFn pageFunc) segmentFunc {
+	return func(ctx context.Context, segment int32, next scanNext) error {
+		startKey, done, err := resume.startKey(segment)
+		if err != nil {
+			s.logger.Error("could not read checkpoint", "segment", segment, "error", err)
+			return err
+		}
+
+		if done {
+			s.logger.Debug("segment complete in checkpoint, skipping", "segment", segment)
+			return nil
+		}
+
+		for !done {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			var output *dynamodb.ScanOutput
+
+			input := newInput()
+			input.ExclusiveStartKey = startKey
+			startKey = nil
+
+			output, err, done = next(input)
+			if err != nil && !errors.Is(err, ddb.ErrNoItems) {
+				s.logger.Error("could not scan table", "error", err)
+				return err
+			}
+
+			if output == nil {
+				break
+			}
+
+			if err = pageFn(ctx, segment, page{Items: output.Items, LastEvaluatedKey: output.LastEvaluatedKey}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// queryPages - queries the table, calling pageFn for each page of items.
+// When resuming from a checkpoint, the query continues from its last evaluated key.
+func (s Service) queryPages(ctx context.Context, resume *Checkpoint, input *dynamodb.QueryInput, pageFn pageFunc) error {
+	startKey, done, err := resume.startKey(0)
+	if err != nil {
+		s.logger.Error("could not read checkpoint", "error", err)
+		return err
+	}
+
+	input.ExclusiveStartKey = startKey
+	next := ddb.QueryIterator(ctx, s.client)
+
+	for !done {
+		var output *dynamodb.QueryOutput
+
+		output, err, done = next(input)
+		if err != nil && !errors.Is(err, ddb.ErrNoItems) {
+			s.logger.Error("could not query table", "error", err)
+			return err
+		}
+
+		if output == nil {
+			break
+		}
+
+		if err = pageFn(ctx, 0, page{Items: output.Items, LastEvaluatedKey: output.LastEvaluatedKey}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readPages - queries the table when a key condition is provided, otherwise scans the table with the requested segments.
+// The filter, where, projection and index options are applied to either operation.
+func (s Service) readPages(ctx context.Context, tableName string, queryOpts *QueryOpts, resume *Checkpoint, pageFn pageFunc) error {
+	exprs, err := buildExpressions(queryOpts)
+	if err != nil {
+		return err
+	}
+
+	if queryOpts.KeyConditionExpression != nil {
+		s.logger.Debug("key condition provided, querying table", "index", aws.ToString(queryOpts.IndexName))
+		return s.queryPages(ctx, resume, &dynamodb.QueryInput{
+			TableName:                 &tableName,
+			IndexName:                 queryOpts.IndexName,
+			Limit:                     queryOpts.Limit,
+			KeyConditionExpression:    queryOpts.KeyConditionExpression,
+			ProjectionExpression:      exprs.projection,
+			FilterExpression:          exprs.filter,
+			ExpressionAttributeNames:  exprs.names,
+			ExpressionAttributeValues: exprs.values,
+		}, pageFn)
+	}
+
+	return s.scanPages(ctx, queryOpts.Segments, resume, func() *dynamodb.ScanInput {
+		return &dynamodb.ScanInput{
+			TableName:                 &tableName,
+			IndexName:                 queryOpts.IndexName,
+			Limit:                     queryOpts.Limit,
+			ProjectionExpression:      exprs.projection,
+			FilterExpression:          exprs.filter,
+			ExpressionAttributeNames:  exprs.names,
+			ExpressionAttributeValues: exprs.values,
+		}
+	}, pageFn)
+}
@@ -0,0 +1,310 @@
+package goety
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetKind - the column type inferred for a field
+type parquetKind int
+
+const (
+	parquetUnknown parquetKind = iota
+	parquetString
+	parquetInt64
+	parquetDouble
+	parquetBool
+	parquetBytes
+	parquetGroup
+	// parquetJSON - a field with conflicting types, written as a json string
+	parquetJSON
+)
+
+// parquetField - the schema inferred for a field from the values seen.
+// Maps are groups of fields, lists and sets are repeated fields.
+type parquetField struct {
+	kind     parquetKind
+	repeated bool
+	fields   map[string]*parquetField
+}
+
+// merge - widens the field to hold the value, conflicting types are widened to json
+func (f *parquetField) merge(value any) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case []any:
+		f.mergeRepeated(func(element *parquetField) {
+			for _, item := range v {
+				element.merge(item)
+			}
+		})
+		return
+	case []string:
+		f.mergeRepeated(func(element *parquetField) {
+			element.mergeKind(parquetString)
+		})
+		return
+	case [][]byte:
+		f.mergeRepeated(func(element *parquetField) {
+			element.mergeKind(parquetBytes)
+		})
+		return
+	}
+
+	if f.repeated {
+		f.widen()
+		return
+	}
+
+	if v, ok := value.(map[string]any); ok {
+		f.mergeKind(parquetGroup)
+		if f.kind != parquetGroup {
+			return
+		}
+		for key, nested := range v {
+			field, ok := f.fields[key]
+			if !ok {
+				field = &parquetField{}
+				f.fields[key] = field
+			}
+			field.merge(nested)
+		}
+		return
+	}
+
+	f.mergeKind(scalarKind(value))
+}
+
+// mergeRepeated - merges a list or set, the element schema is held by the field itself.
+// Nested lists widen to json.
+func (f *parquetField) mergeRepeated(mergeElements func(element *parquetField)) {
+	if f.kind == parquetJSON {
+		return
+	}
+	if f.kind != parquetUnknown && !f.repeated {
+		f.widen()
+		return
+	}
+
+	element := f.element()
+	mergeElements(element)
+	if element.repeated {
+		f.widen()
+		return
+	}
+
+	f.repeated = true
+	f.kind = element.kind
+	f.fields = element.fields
+}
+
+// element - returns the schema of a single element of a repeated field
+func (f *parquetField) element() *parquetField {
+	return &parquetField{kind: f.kind, fields: f.fields}
+}
+
+// mergeKind - merges a kind into the field, integers widen to doubles and, any other conflict widens to json
+func (f *parquetField) mergeKind(kind parquetKind) {
+	switch {
+	case f.kind == kind:
+	case f.kind == parquetUnknown:
+		f.kind = kind
+		if kind == parquetGroup {
+			f.fields = map[string]*parquetField{}
+		}
+	case f.kind == parquetInt64 && kind == parquetDouble, f.kind == parquetDouble && kind == parquetInt64:
+		f.kind = parquetDouble
+	default:
+		f.widen()
+	}
+}
+
+// widen - widens the field to a json string
+func (f *parquetField) widen() {
+	f.kind = parquetJSON
+	f.repeated = false
+	f.fields = nil
+}
+
+// scalarKind - returns the kind of a flattened scalar value
+func scalarKind(value any) parquetKind {
+	switch v := value.(type) {
+	case string:
+		return parquetString
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return parquetInt64
+		}
+		return parquetDouble
+	case float64:
+		return parquetDouble
+	case bool:
+		return parquetBool
+	case []byte:
+		return parquetBytes
+	}
+
+	return parquetJSON
+}
+
+// node - returns the parquet node for the field, fields are optional as items need not share attributes
+func (f *parquetField) node() parquet.Node {
+	var node parquet.Node
+
+	switch f.kind {
+	case parquetInt64:
+		node = parquet.Int(64)
+	case parquetDouble:
+		node = parquet.Leaf(parquet.DoubleType)
+	case parquetBool:
+		node = parquet.Leaf(parquet.BooleanType)
+	case parquetBytes:
+		node = parquet.Leaf(parquet.ByteArrayType)
+	case parquetGroup:
+		node = f.group()
+		if len(f.fields) == 0 {
+			node = parquet.JSON()
+		}
+	case parquetJSON:
+		node = parquet.JSON()
+	default:
+		node = parquet.String()
+	}
+
+	if f.repeated {
+		return parquet.Repeated(node)
+	}
+
+	return parquet.Optional(node)
+}
+
+// group - returns the parquet group of the nested fields
+func (f *parquetField) group() parquet.Group {
+	group := parquet.Group{}
+	for name, field := range f.fields {
+		group[name] = field.node()
+	}
+
+	return group
+}
+
+// value - converts a spooled value to the type of the column, values that cannot be converted are written as null
+func (f *parquetField) value(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	if f.repeated {
+		elements, ok := value.([]any)
+		if !ok {
+			return nil
+		}
+		element := f.element()
+		values := make([]any, 0, len(elements))
+		for _, item := range elements {
+			if converted := element.value(item); converted != nil {
+				values = append(values, converted)
+			}
+		}
+		return values
+	}
+
+	if f.kind == parquetGroup && len(f.fields) == 0 {
+		return f.jsonValue(value)
+	}
+
+	switch f.kind {
+	case parquetJSON:
+		return f.jsonValue(value)
+	case parquetGroup:
+		nested, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		row := map[string]any{}
+		for name, field := range f.fields {
+			row[name] = field.value(nested[name])
+		}
+		return row
+	case parquetInt64:
+		number, _ := value.(json.Number)
+		converted, err := number.Int64()
+		if err != nil {
+			return nil
+		}
+		return converted
+	case parquetDouble:
+		number, _ := value.(json.Number)
+		converted, err := number.Float64()
+		if err != nil {
+			return nil
+		}
+		return converted
+	case parquetBytes:
+		encoded, _ := value.(string)
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+
+	return value
+}
+
+// jsonValue - encodes a value written to a json column
+func (f *parquetField) jsonValue(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	return string(data)
+}
+
+// parquetItemWriter - writes items as a parquet file.
+// The schema is inferred from every item, so items are spooled to a temporary file and, written once the schema is known.
+type parquetItemWriter struct {
+	writer Writer
+	spool  itemSpool
+	schema *parquetField
+}
+
+func newParquetItemWriter(writer Writer) *parquetItemWriter {
+	return &parquetItemWriter{
+		writer: writer,
+		schema: &parquetField{kind: parquetGroup, fields: map[string]*parquetField{}},
+	}
+}
+
+func (w *parquetItemWriter) begin() error {
+	return nil
+}
+
+func (w *parquetItemWriter) write(item map[string]any, _ int) error {
+	w.schema.merge(item)
+	return w.spool.add(item)
+}
+
+func (w *parquetItemWriter) end() error {
+	defer w.spool.close()
+
+	if len(w.schema.fields) == 0 {
+		return nil
+	}
+
+	schema := parquet.NewSchema("item", w.schema.group())
+	writer := parquet.NewWriter(w.writer, schema, parquet.Compression(&parquet.Snappy))
+
+	err := w.spool.each(func(item map[string]any) error {
+		return writer.Write(w.schema.value(item))
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
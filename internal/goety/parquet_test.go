@@ -0,0 +1,98 @@
+package goety
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/code-gorilla-au/odize"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestParquetItemWriter(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	readRows := func(t *testing.T, data []byte) (*parquet.Schema, []map[string]any) {
+		reader := parquet.NewReader(bytes.NewReader(data))
+		defer reader.Close()
+
+		rows := []map[string]any{}
+		for {
+			row := map[string]any{}
+			err := reader.Read(&row)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			odize.AssertNoError(t, err)
+			rows = append(rows, row)
+		}
+
+		return reader.Schema(), rows
+	}
+
+	err := group.
+		Test("should infer schema from flattened items", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writer := newParquetItemWriter(&buf)
+
+			items := []map[string]any{
+				{
+					"pk":      "pk-0",
+					"count":   json.Number("10"),
+					"price":   json.Number("1.5"),
+					"active":  true,
+					"tags":    []string{"a", "b"},
+					"address": map[string]any{"city": "Brisbane"},
+				},
+				{
+					"pk":    "pk-1",
+					"count": json.Number("11"),
+					"price": json.Number("2"),
+					"mixed": "text",
+				},
+				{
+					"pk":    "pk-2",
+					"mixed": json.Number("1"),
+				},
+			}
+
+			odize.AssertNoError(t, writer.begin())
+			for i, item := range items {
+				odize.AssertNoError(t, writer.write(item, i))
+			}
+			odize.AssertNoError(t, writer.end())
+
+			schema, rows := readRows(t, buf.Bytes())
+
+			expected := `message item {
+	optional boolean active;
+	optional group address {
+		optional binary city (STRING);
+	}
+	optional int64 count (INT(64,true));
+	optional binary mixed (JSON);
+	optional binary pk (STRING);
+	optional double price;
+	repeated binary tags (STRING);
+}`
+			odize.AssertEqual(t, expected, schema.String())
+			odize.AssertEqual(t, 3, len(rows))
+			odize.AssertEqual(t, int64(10), rows[0]["count"])
+			odize.AssertEqual(t, 2.0, rows[1]["price"])
+			odize.AssertEqual(t, []any{"a", "b"}, rows[0]["tags"])
+			odize.AssertEqual(t, "Brisbane", rows[0]["address"].(map[string]any)["city"])
+		}).
+		Test("should write nothing without items", func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writer := newParquetItemWriter(&buf)
+
+			odize.AssertNoError(t, writer.begin())
+			odize.AssertNoError(t, writer.end())
+			odize.AssertEqual(t, 0, buf.Len())
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
@@ -0,0 +1,134 @@
+package goety
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrInvalidSortCondition = errors.New("invalid sort key condition")
+	ErrInvalidKeyValue      = errors.New("invalid key value")
+)
+
+// SortOperator - the comparison a sort key condition applies
+type SortOperator string
+
+const (
+	SortEqual          SortOperator = "="
+	SortLess           SortOperator = "<"
+	SortLessOrEqual    SortOperator = "<="
+	SortGreater        SortOperator = ">"
+	SortGreaterOrEqual SortOperator = ">="
+	SortBeginsWith     SortOperator = "begins_with"
+	SortBetween        SortOperator = "between"
+)
+
+// SortCondition - a condition on the sort key, narrowing the items of a partition
+type SortCondition struct {
+	Operator SortOperator
+	Values   []string
+}
+
+// ParseSortCondition - parses a sort key condition written as the operator followed by the value.
+// Between takes a lower and upper value separated by "and".
+//
+// Example:
+//
+//	ParseSortCondition("begins_with order#")
+//	ParseSortCondition("between 2024-01-01 and 2024-12-31")
+func ParseSortCondition(condition string) (*SortCondition, error) {
+	operator, value, ok := strings.Cut(strings.TrimSpace(condition), " ")
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("%w: expected an operator and a value, got %q", ErrInvalidSortCondition, condition)
+	}
+
+	switch SortOperator(strings.ToLower(operator)) {
+	case SortEqual, SortLess, SortLessOrEqual, SortGreater, SortGreaterOrEqual, SortBeginsWith:
+		return &SortCondition{Operator: SortOperator(strings.ToLower(operator)), Values: []string{value}}, nil
+	case SortBetween:
+		lower, upper, ok := strings.Cut(value, " and ")
+		if !ok {
+			return nil, fmt.Errorf("%w: between requires a lower and upper value, got %q", ErrInvalidSortCondition, value)
+		}
+		return &SortCondition{Operator: SortBetween, Values: []string{strings.TrimSpace(lower), strings.TrimSpace(upper)}}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported operator %q", ErrInvalidSortCondition, operator)
+}
+
+// expression - returns the condition on the sort key alias, adding the values to the attribute values
+func (c *SortCondition) expression(alias string, kind types.ScalarAttributeType, values map[string]types.AttributeValue) (string, error) {
+	placeholders := make([]string, len(c.Values))
+	for i, value := range c.Values {
+		av, err := keyValue(kind, value)
+		if err != nil {
+			return "", err
+		}
+		placeholders[i] = fmt.Sprintf(":goetySort%d", i)
+		values[placeholders[i]] = av
+	}
+
+	switch c.Operator {
+	case SortBeginsWith:
+		return fmt.Sprintf("begins_with(%s, %s)", alias, placeholders[0]), nil
+	case SortBetween:
+		return fmt.Sprintf("%s BETWEEN %s AND %s", alias, placeholders[0], placeholders[1]), nil
+	}
+
+	return fmt.Sprintf("%s %s %s", alias, c.Operator, placeholders[0]), nil
+}
+
+// partitionCondition - returns the key condition of the partition and, the optional sort key condition.
+// Key attribute names use the aliases of the key projection, values are typed from the table attribute definitions.
+func partitionCondition(keys TableKeys, partition string, sort *SortCondition, names map[string]string, values map[string]types.AttributeValue) (string, error) {
+	value, err := keyValue(keys.partitionType, partition)
+	if err != nil {
+		return "", fmt.Errorf("partition key %s: %w", keys.PartitionKey, err)
+	}
+
+	names["#goetyKey0"] = keys.PartitionKey
+	values[":goetyPartition"] = value
+	condition := "#goetyKey0 = :goetyPartition"
+
+	if sort == nil {
+		return condition, nil
+	}
+
+	if keys.SortKey == "" {
+		return "", fmt.Errorf("%w: table has no sort key", ErrInvalidSortCondition)
+	}
+
+	names["#goetyKey1"] = keys.SortKey
+	sortExpression, err := sort.expression("#goetyKey1", keys.sortType, values)
+	if err != nil {
+		return "", fmt.Errorf("sort key %s: %w", keys.SortKey, err)
+	}
+
+	return condition + " AND " + sortExpression, nil
+}
+
+// keyValue - converts a key value to an attribute value of the key type, string keys are assumed when the type is not known.
+// Binary keys are base64 encoded.
+func keyValue(kind types.ScalarAttributeType, value string) (types.AttributeValue, error) {
+	switch kind {
+	case types.ScalarAttributeTypeN:
+		number, err := csvNumber(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeyValue, err)
+		}
+		return number, nil
+	case types.ScalarAttributeTypeB:
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeyValue, err)
+		}
+		return &types.AttributeValueMemberB{Value: data}, nil
+	}
+
+	return &types.AttributeValueMemberS{Value: value}, nil
+}
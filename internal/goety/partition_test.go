@@ -0,0 +1,88 @@
+package goety
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestParseSortCondition(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should parse a comparison", func(t *testing.T) {
+			condition, err := ParseSortCondition("<= 2024-06-30")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, &SortCondition{Operator: SortLessOrEqual, Values: []string{"2024-06-30"}}, condition)
+		}).
+		Test("should parse begins with, keeping spaces in the value", func(t *testing.T) {
+			condition, err := ParseSortCondition("BEGINS_WITH order #1")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, &SortCondition{Operator: SortBeginsWith, Values: []string{"order #1"}}, condition)
+		}).
+		Test("should parse between", func(t *testing.T) {
+			condition, err := ParseSortCondition("between 2024-01-01 and 2024-12-31")
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, &SortCondition{Operator: SortBetween, Values: []string{"2024-01-01", "2024-12-31"}}, condition)
+		}).
+		Test("should return error for between without an upper value", func(t *testing.T) {
+			_, err := ParseSortCondition("between 2024-01-01")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidSortCondition))
+		}).
+		Test("should return error without a value", func(t *testing.T) {
+			_, err := ParseSortCondition("begins_with")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidSortCondition))
+		}).
+		Test("should return error for an unsupported operator", func(t *testing.T) {
+			_, err := ParseSortCondition("contains order")
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidSortCondition))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestPartitionCondition(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var names map[string]string
+	var values map[string]types.AttributeValue
+
+	group.BeforeEach(func() {
+		names = map[string]string{}
+		values = map[string]types.AttributeValue{}
+	})
+
+	err := group.
+		Test("should return the partition condition", func(t *testing.T) {
+			condition, err := partitionCondition(TableKeys{PartitionKey: "pk", partitionType: types.ScalarAttributeTypeN}, "42", nil, names, values)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "#goetyKey0 = :goetyPartition", condition)
+			odize.AssertEqual(t, map[string]string{"#goetyKey0": "pk"}, names)
+			odize.AssertEqual(t, "42", values[":goetyPartition"].(*types.AttributeValueMemberN).Value)
+		}).
+		Test("should add begins with on the sort key", func(t *testing.T) {
+			sort := &SortCondition{Operator: SortBeginsWith, Values: []string{"order#"}}
+			condition, err := partitionCondition(TableKeys{PartitionKey: "pk", SortKey: "sk"}, "tenant-1", sort, names, values)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "#goetyKey0 = :goetyPartition AND begins_with(#goetyKey1, :goetySort0)", condition)
+			odize.AssertEqual(t, "order#", values[":goetySort0"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should decode binary keys", func(t *testing.T) {
+			_, err := partitionCondition(TableKeys{PartitionKey: "pk", partitionType: types.ScalarAttributeTypeB}, "aGVsbG8=", nil, names, values)
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, []byte("hello"), values[":goetyPartition"].(*types.AttributeValueMemberB).Value)
+		}).
+		Test("should return error for a sort condition on a table without a sort key", func(t *testing.T) {
+			sort := &SortCondition{Operator: SortEqual, Values: []string{"1"}}
+			_, err := partitionCondition(TableKeys{PartitionKey: "pk"}, "tenant-1", sort, names, values)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidSortCondition))
+		}).
+		Test("should return error for a partition value that does not match the key type", func(t *testing.T) {
+			_, err := partitionCondition(TableKeys{PartitionKey: "pk", partitionType: types.ScalarAttributeTypeN}, "tenant-1", nil, names, values)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidKeyValue))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
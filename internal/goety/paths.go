@@ -0,0 +1,79 @@
+package goety
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// lookupAttribute - looks up an attribute, an attribute named by the path is preferred over a dotted path into nested maps
+func lookupAttribute(item map[string]types.AttributeValue, path string) (types.AttributeValue, bool) {
+	if value, ok := item[path]; ok {
+		return value, true
+	}
+
+	parent, name, ok := parentMap(item, path, false)
+	if !ok {
+		return nil, false
+	}
+
+	value, ok := parent[name]
+	return value, ok
+}
+
+// setAttribute - sets an attribute, nested maps on a dotted path are created when missing
+func setAttribute(item map[string]types.AttributeValue, path string, value types.AttributeValue) {
+	if _, ok := item[path]; ok || !strings.Contains(path, ".") {
+		item[path] = value
+		return
+	}
+
+	parent, name, ok := parentMap(item, path, true)
+	if !ok {
+		item[path] = value
+		return
+	}
+
+	parent[name] = value
+}
+
+// deleteAttribute - deletes an attribute
+func deleteAttribute(item map[string]types.AttributeValue, path string) {
+	if _, ok := item[path]; ok {
+		delete(item, path)
+		return
+	}
+
+	parent, name, ok := parentMap(item, path, false)
+	if !ok {
+		return
+	}
+
+	delete(parent, name)
+}
+
+// parentMap - walks a dotted path, returning the map holding the last attribute and its name.
+// When create is set, missing maps on the path are created.
+func parentMap(item map[string]types.AttributeValue, path string, create bool) (map[string]types.AttributeValue, string, bool) {
+	parts := strings.Split(path, ".")
+	current := item
+
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part]
+		if !ok {
+			if !create {
+				return nil, "", false
+			}
+			next = &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}
+			current[part] = next
+		}
+
+		nested, ok := next.(*types.AttributeValueMemberM)
+		if !ok {
+			return nil, "", false
+		}
+		current = nested.Value
+	}
+
+	return current, parts[len(parts)-1], true
+}
@@ -0,0 +1,256 @@
+package goety
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+)
+
+const (
+	// jsonSchemaDialect - the json schema version inferred schemas are written in
+	jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	// unknownEntity - the entity of items without a discriminator attribute or key prefix
+	unknownEntity = "unknown"
+)
+
+// Schemas - inferred json schemas by entity type
+type Schemas map[string]map[string]any
+
+// InferSchema - reads the table, or a sample of the table, and infers a json schema for each entity type.
+// Items are grouped by the entity attribute when it is provided and the item has a string value for it.
+// Otherwise items are grouped by the prefix of their partition and sort keys, up to the first "#" e.g. USER#123 is a USER.
+// Each property records its dynamodb type as "x-dynamodb-type", attributes present in every item of an entity are required.
+//
+// Example:
+//
+//	schemas, err := InferSchema(ctx, "my-table", WithEntityAttribute("entityType"), WithSample(10))
+func (s Service) InferSchema(ctx context.Context, tableName string, opts ...QueryFuncOpts) (Schemas, error) {
+	s.emitter.Publish(fmt.Sprintf("inferring schema of table %s", tableName))
+
+	queryOpts := WithQueryOptions(opts)
+	if err := validateSample(queryOpts.Sample); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.resolveTableKeys(ctx, tableName, TableKeys{})
+	if err != nil {
+		return nil, err
+	}
+
+	var mx sync.Mutex
+	entities := map[string]*schemaNode{}
+	inferred := 0
+
+	pageFn := func(ctx context.Context, segment int32, p page) error {
+		mx.Lock()
+		defer mx.Unlock()
+
+		for _, item := range p.Items {
+			converted, err := ddb.ConvertAVValue(item)
+			if err != nil {
+				s.logger.Error("could not convert item", "error", err)
+				return err
+			}
+
+			entity := entityType(converted, keys, queryOpts.EntityAttribute)
+			node, ok := entities[entity]
+			if !ok {
+				node = newSchemaNode()
+				entities[entity] = node
+			}
+			node.addItem(converted)
+		}
+
+		inferred += len(p.Items)
+		s.emitter.Publish(fmt.Sprintf("inferred schema from %d items", inferred))
+		return nil
+	}
+
+	if err = s.readSample(ctx, tableName, queryOpts, pageFn); err != nil {
+		return nil, err
+	}
+
+	schemas := Schemas{}
+	for entity, node := range entities {
+		schema := node.schema()
+		delete(schema, "x-dynamodb-type")
+		schema["$schema"] = jsonSchemaDialect
+		schema["title"] = entity
+		schema["description"] = fmt.Sprintf("inferred from %d items of table %s", node.count, tableName)
+		schemas[entity] = schema
+	}
+
+	s.emitter.Publish(fmt.Sprintf("schema inferred, %d entity types from %d items", len(schemas), inferred))
+	s.logger.Info("schema inferred", "entities", len(schemas), "items", inferred)
+	return schemas, nil
+}
+
+// entityType - returns the entity of an item from the entity attribute, or the prefixes of its keys.
+// When the sort key has a different prefix to the partition key, the entity is both prefixes e.g. USER/ORDER.
+func entityType(item map[string]ddb.AVer, keys TableKeys, entityAttribute string) string {
+	if entityAttribute != "" {
+		if value, ok := item[entityAttribute].(ddb.AVString); ok && value.S != "" {
+			return value.S
+		}
+	}
+
+	partition := keyPrefix(item[keys.PartitionKey])
+	sort := keyPrefix(item[keys.SortKey])
+
+	switch {
+	case partition == "" && sort == "":
+		return unknownEntity
+	case partition == "":
+		return sort
+	case sort == "" || sort == partition:
+		return partition
+	}
+
+	return partition + "/" + sort
+}
+
+// keyPrefix - returns the text of a string key value up to the first "#", empty when the value has no prefix
+func keyPrefix(value ddb.AVer) string {
+	key, ok := value.(ddb.AVString)
+	if !ok {
+		return ""
+	}
+
+	prefix, _, found := strings.Cut(key.S, "#")
+	if !found {
+		return ""
+	}
+
+	return prefix
+}
+
+// schemaNode - the dynamodb types observed for an attribute, with the members of maps and the elements of lists
+type schemaNode struct {
+	count      int
+	types      map[string]int
+	properties map[string]*schemaNode
+	items      *schemaNode
+}
+
+// newSchemaNode - creates an empty schema node
+func newSchemaNode() *schemaNode {
+	return &schemaNode{
+		types:      map[string]int{},
+		properties: map[string]*schemaNode{},
+	}
+}
+
+// addItem - adds the attributes of an item, the item is observed as a map
+func (n *schemaNode) addItem(item map[string]ddb.AVer) {
+	n.add(ddb.AVMap{M: avMembers(item)})
+}
+
+// add - adds an observed value, maps and lists add their members and elements to child nodes
+func (n *schemaNode) add(value any) {
+	n.count++
+	n.types[avType(value)]++
+
+	switch v := value.(type) {
+	case ddb.AVMap:
+		for name, member := range v.M {
+			property, ok := n.properties[name]
+			if !ok {
+				property = newSchemaNode()
+				n.properties[name] = property
+			}
+			property.add(member)
+		}
+	case ddb.AVList:
+		for _, element := range v.L {
+			if n.items == nil {
+				n.items = newSchemaNode()
+			}
+			n.items.add(element)
+		}
+	}
+}
+
+// schema - returns the json schema of the observed types, values observed with more than one type are any of the type schemas
+func (n *schemaNode) schema() map[string]any {
+	observed := slices.Sorted(maps.Keys(n.types))
+
+	if len(observed) == 1 {
+		return n.typeSchema(observed[0])
+	}
+
+	anyOf := []map[string]any{}
+	for _, attrType := range observed {
+		anyOf = append(anyOf, n.typeSchema(attrType))
+	}
+
+	return map[string]any{"anyOf": anyOf}
+}
+
+// typeSchema - returns the json schema of a dynamodb type, maps require the members present in every observed map
+func (n *schemaNode) typeSchema(attrType string) map[string]any {
+	schema := map[string]any{"x-dynamodb-type": attrType}
+
+	switch attrType {
+	case "S":
+		schema["type"] = "string"
+	case "N":
+		schema["type"] = "number"
+	case "B":
+		schema["type"] = "string"
+		schema["contentEncoding"] = "base64"
+	case "BOOL":
+		schema["type"] = "boolean"
+	case "NULL":
+		schema["type"] = "null"
+	case "SS":
+		schema["type"] = "array"
+		schema["uniqueItems"] = true
+		schema["items"] = map[string]any{"type": "string"}
+	case "NS":
+		schema["type"] = "array"
+		schema["uniqueItems"] = true
+		schema["items"] = map[string]any{"type": "number"}
+	case "BS":
+		schema["type"] = "array"
+		schema["uniqueItems"] = true
+		schema["items"] = map[string]any{"type": "string", "contentEncoding": "base64"}
+	case "M":
+		properties := map[string]any{}
+		required := []string{}
+		for name, property := range n.properties {
+			properties[name] = property.schema()
+			if property.count == n.types["M"] {
+				required = append(required, name)
+			}
+		}
+		slices.Sort(required)
+
+		schema["type"] = "object"
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	case "L":
+		schema["type"] = "array"
+		if n.items != nil {
+			schema["items"] = n.items.schema()
+		}
+	}
+
+	return schema
+}
+
+// avMembers - returns the attributes of a converted item as map members
+func avMembers(item map[string]ddb.AVer) map[string]any {
+	members := make(map[string]any, len(item))
+	for name, value := range item {
+		members[name] = value
+	}
+
+	return members
+}
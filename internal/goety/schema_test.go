@@ -0,0 +1,176 @@
+package goety
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestService_InferSchema(t *testing.T) {
+	var client DynamoClientMock
+	var service Service
+	logger := logging.New(true)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	items := []map[string]types.AttributeValue{
+		{
+			"pk":    &types.AttributeValueMemberS{Value: "USER#1"},
+			"sk":    &types.AttributeValueMemberS{Value: "PROFILE"},
+			"type":  &types.AttributeValueMemberS{Value: "User"},
+			"name":  &types.AttributeValueMemberS{Value: "Jane"},
+			"age":   &types.AttributeValueMemberN{Value: "30"},
+			"roles": &types.AttributeValueMemberSS{Value: []string{"admin"}},
+		},
+		{
+			"pk":   &types.AttributeValueMemberS{Value: "USER#2"},
+			"sk":   &types.AttributeValueMemberS{Value: "PROFILE"},
+			"type": &types.AttributeValueMemberS{Value: "User"},
+			"name": &types.AttributeValueMemberS{Value: "John"},
+			"age":  &types.AttributeValueMemberS{Value: "unknown"},
+		},
+		{
+			"pk": &types.AttributeValueMemberS{Value: "USER#1"},
+			"sk": &types.AttributeValueMemberS{Value: "ORDER#1"},
+			"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"city": &types.AttributeValueMemberS{Value: "Brisbane"},
+			}},
+			"lines": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberN{Value: "1"},
+			}},
+		},
+	}
+
+	group := odize.NewGroup(t, nil)
+
+	group.BeforeEach(func() {
+		client = DynamoClientMock{
+			ScanFunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{Items: items}, nil
+			},
+			DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", "sk"), nil
+			},
+		}
+
+		service = Service{
+			client: &client,
+			logger: logger,
+			emitter: &mockEmitter{
+				publishFunc: func(message string) {},
+			},
+		}
+	})
+
+	err := group.
+		Test("should group items by key prefix", func(t *testing.T) {
+			schemas, err := service.InferSchema(ctx, "my-table")
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 2, len(schemas))
+			odize.AssertEqual(t, "USER", schemas["USER"]["title"])
+			odize.AssertEqual(t, jsonSchemaDialect, schemas["USER/ORDER"]["$schema"])
+		}).
+		Test("should group items by the entity attribute", func(t *testing.T) {
+			schemas, err := service.InferSchema(ctx, "my-table", WithEntityAttribute("type"))
+			odize.AssertNoError(t, err)
+
+			_, ok := schemas["User"]
+			odize.AssertTrue(t, ok)
+			_, ok = schemas["USER/ORDER"]
+			odize.AssertTrue(t, ok)
+		}).
+		Test("should require attributes present in every item", func(t *testing.T) {
+			schemas, err := service.InferSchema(ctx, "my-table")
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, []string{"age", "name", "pk", "sk", "type"}, schemas["USER"]["required"])
+		}).
+		Test("should infer property types", func(t *testing.T) {
+			schemas, err := service.InferSchema(ctx, "my-table")
+			odize.AssertNoError(t, err)
+
+			data, err := json.Marshal(schemas["USER"]["properties"])
+			odize.AssertNoError(t, err)
+
+			var properties map[string]any
+			odize.AssertNoError(t, json.Unmarshal(data, &properties))
+
+			odize.AssertEqual(t, map[string]any{
+				"anyOf": []any{
+					map[string]any{"type": "number", "x-dynamodb-type": "N"},
+					map[string]any{"type": "string", "x-dynamodb-type": "S"},
+				},
+			}, properties["age"])
+			odize.AssertEqual(t, map[string]any{
+				"type":            "array",
+				"uniqueItems":     true,
+				"items":           map[string]any{"type": "string"},
+				"x-dynamodb-type": "SS",
+			}, properties["roles"])
+		}).
+		Test("should infer nested maps and lists", func(t *testing.T) {
+			schemas, err := service.InferSchema(ctx, "my-table")
+			odize.AssertNoError(t, err)
+
+			properties := schemas["USER/ORDER"]["properties"].(map[string]any)
+			odize.AssertEqual(t, map[string]any{
+				"type":            "object",
+				"x-dynamodb-type": "M",
+				"properties": map[string]any{
+					"city": map[string]any{"type": "string", "x-dynamodb-type": "S"},
+				},
+				"required": []string{"city"},
+			}, properties["address"])
+			odize.AssertEqual(t, map[string]any{
+				"type":            "array",
+				"x-dynamodb-type": "L",
+				"items":           map[string]any{"type": "number", "x-dynamodb-type": "N"},
+			}, properties["lines"])
+		}).
+		Test("should return error if scan fails", func(t *testing.T) {
+			expectedErr := errors.New("scan error")
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return nil, expectedErr
+			}
+
+			_, err := service.InferSchema(ctx, "my-table")
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func TestEntityType(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	keys := TableKeys{PartitionKey: "pk", SortKey: "sk"}
+
+	err := group.
+		Test("should use the partition key prefix", func(t *testing.T) {
+			item := map[string]ddb.AVer{"pk": ddb.AVString{S: "TENANT#1"}, "sk": ddb.AVString{S: "TENANT#1"}}
+			odize.AssertEqual(t, "TENANT", entityType(item, keys, ""))
+		}).
+		Test("should use the sort key prefix without a partition key prefix", func(t *testing.T) {
+			item := map[string]ddb.AVer{"pk": ddb.AVString{S: "abc"}, "sk": ddb.AVString{S: "ORDER#1"}}
+			odize.AssertEqual(t, "ORDER", entityType(item, keys, ""))
+		}).
+		Test("should fall back to the key prefix without an entity attribute value", func(t *testing.T) {
+			item := map[string]ddb.AVer{"pk": ddb.AVString{S: "USER#1"}, "type": ddb.AVNumber{N: "1"}}
+			odize.AssertEqual(t, "USER", entityType(item, keys, "type"))
+		}).
+		Test("should be unknown without a key prefix", func(t *testing.T) {
+			item := map[string]ddb.AVer{"pk": ddb.AVNumber{N: "1"}}
+			odize.AssertEqual(t, unknownEntity, entityType(item, keys, ""))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
@@ -0,0 +1,460 @@
+package goety
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+)
+
+const (
+	// statsSampleSegments - the number of segments a sampled scan divides the table into, a sample scans a subset of the segments
+	statsSampleSegments = 100
+	// defaultTopPartitions - the number of hottest partitions reported when none is provided
+	defaultTopPartitions = 10
+	// maxItemSize - the largest item dynamodb stores, larger estimates are counted as this size in the size histogram
+	maxItemSize = 400 * 1024
+	// exactItemSize - item sizes below this are counted exactly, larger sizes are counted in buckets of itemSizeBucket bytes
+	exactItemSize  = 1024
+	itemSizeBucket = 64
+)
+
+var (
+	ErrInvalidSample = errors.New("invalid sample percentage")
+)
+
+// TableStats - a profile of the items in a table
+type TableStats struct {
+	TableName string `json:"tableName"`
+	// Items - the number of items scanned
+	Items int `json:"items"`
+	// SamplePercent - the percentage of the table's segments scanned, 100 when the whole table is scanned
+	SamplePercent float64 `json:"samplePercent"`
+	// EstimatedItems - the number of items in the table, estimated from the sample
+	EstimatedItems int              `json:"estimatedItems"`
+	TotalSize      int64            `json:"totalSize"`
+	P50Size        int              `json:"p50Size"`
+	P95Size        int              `json:"p95Size"`
+	MaxSize        int              `json:"maxSize"`
+	Attributes     []StatCount      `json:"attributes"`
+	Types          []StatCount      `json:"types"`
+	HotPartitions  []PartitionStats `json:"hotPartitions"`
+	Duration       time.Duration    `json:"-"`
+	sizes          *sizeHistogram
+	partitions     map[string]*PartitionStats
+	attributes     map[string]int
+	types          map[string]int
+}
+
+// StatCount - the number of times an attribute name or attribute type occurs
+type StatCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// PartitionStats - the number of items and their total size in a partition
+type PartitionStats struct {
+	Key   string `json:"key"`
+	Items int    `json:"items"`
+	Size  int64  `json:"size"`
+}
+
+// Stats - scans the table and reports the item count, item sizes, attribute name frequency, attribute type distribution and, the hottest partitions.
+// Item sizes are estimated with the dynamodb item size rules. A sample scans a percentage of the table's segments, the item count is estimated from the sample.
+//
+// Example:
+//
+//	stats, err := Stats(ctx, "my-table", WithSegments(4), WithSample(10))
+func (s Service) Stats(ctx context.Context, tableName string, opts ...QueryFuncOpts) (*TableStats, error) {
+	s.emitter.Publish(fmt.Sprintf("profiling table %s", tableName))
+	now := time.Now()
+
+	queryOpts := WithQueryOptions(opts)
+	if err := validateSample(queryOpts.Sample); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.resolveTableKeys(ctx, tableName, TableKeys{})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := newTableStats(tableName)
+
+	var mx sync.Mutex
+	pageFn := func(ctx context.Context, segment int32, p page) error {
+		mx.Lock()
+		defer mx.Unlock()
+
+		for _, item := range p.Items {
+			if err := stats.add(item, keys.PartitionKey); err != nil {
+				s.logger.Error("could not profile item", "error", err)
+				return err
+			}
+		}
+
+		s.emitter.Publish(fmt.Sprintf("profiled %d items", stats.Items))
+		return nil
+	}
+
+	stats.SamplePercent = samplePercent(queryOpts.Sample)
+	if err = s.readSample(ctx, tableName, queryOpts, pageFn); err != nil {
+		return nil, err
+	}
+
+	stats.summarise(cmp.Or(queryOpts.TopPartitions, defaultTopPartitions))
+	stats.Duration = time.Since(now)
+
+	s.emitter.Publish(fmt.Sprintf("profile complete, %d items scanned", stats.Items))
+	s.logger.Info("profile complete", "items", stats.Items)
+	return stats, nil
+}
+
+// readSample - scans a sample of the table's segments when a sample percentage is provided, otherwise reads the whole table
+func (s Service) readSample(ctx context.Context, tableName string, queryOpts *QueryOpts, pageFn pageFunc) error {
+	if samplePercent(queryOpts.Sample) < 100 {
+		return s.scanSample(ctx, tableName, queryOpts, pageFn)
+	}
+
+	return s.readPages(ctx, tableName, queryOpts, nil, pageFn)
+}
+
+// validateSample - ensures the sample is a percentage, zero reads the whole table
+func validateSample(sample float64) error {
+	if sample < 0 || sample > 100 {
+		return fmt.Errorf("%w: %v", ErrInvalidSample, sample)
+	}
+
+	return nil
+}
+
+// samplePercent - returns the percentage of the table's segments a sample scans, 100 when the whole table is read
+func samplePercent(sample float64) float64 {
+	if sample <= 0 || sample >= 100 {
+		return 100
+	}
+
+	return float64(len(sampleSegments(sample))) * 100 / statsSampleSegments
+}
+
+// scanSample - scans an evenly spread subset of the table's segments, with up to the requested number of segments scanned in parallel
+func (s Service) scanSample(ctx context.Context, tableName string, queryOpts *QueryOpts, pageFn pageFunc) error {
+	exprs, err := buildExpressions(queryOpts)
+	if err != nil {
+		return err
+	}
+
+	segments := sampleSegments(queryOpts.Sample)
+	s.logger.Debug("starting sampled scan", "segments", len(segments), "totalSegments", statsSampleSegments)
+
+	return s.scanSegmentSubset(ctx, segments, statsSampleSegments, int(queryOpts.Segments), s.segmentPages(nil, func() *dynamodb.ScanInput {
+		return &dynamodb.ScanInput{
+			TableName:                 &tableName,
+			Limit:                     queryOpts.Limit,
+			FilterExpression:          exprs.filter,
+			ExpressionAttributeNames:  exprs.names,
+			ExpressionAttributeValues: exprs.values,
+		}
+	}, pageFn))
+}
+
+// sampleSegments - returns the segments to scan for the sample percentage, spread evenly across the table
+func sampleSegments(sample float64) []int32 {
+	count := max(1, int(math.Round(sample/100*statsSampleSegments)))
+
+	segments := make([]int32, 0, count)
+	for i := range count {
+		segments = append(segments, int32(i*statsSampleSegments/count))
+	}
+
+	return segments
+}
+
+// newTableStats - creates empty stats for the table
+func newTableStats(tableName string) *TableStats {
+	return &TableStats{
+		TableName:  tableName,
+		sizes:      newSizeHistogram(),
+		partitions: map[string]*PartitionStats{},
+		attributes: map[string]int{},
+		types:      map[string]int{},
+	}
+}
+
+// add - adds an item to the stats, converting its attribute values to find their types and sizes
+func (t *TableStats) add(item map[string]types.AttributeValue, partitionKey string) error {
+	converted, err := ddb.ConvertAVValue(item)
+	if err != nil {
+		return err
+	}
+
+	size := 0
+	for name, value := range converted {
+		size += len(name) + avSize(value)
+		t.attributes[name]++
+		t.types[avType(value)]++
+	}
+
+	t.Items++
+	t.TotalSize += int64(size)
+	t.MaxSize = max(t.MaxSize, size)
+	t.sizes.add(size)
+
+	if value, ok := converted[partitionKey]; ok {
+		key := partitionValue(value)
+
+		partition, ok := t.partitions[key]
+		if !ok {
+			partition = &PartitionStats{Key: key}
+			t.partitions[key] = partition
+		}
+		partition.Items++
+		partition.Size += int64(size)
+	}
+
+	return nil
+}
+
+// summarise - calculates the size percentiles, sorts the attribute counts and, keeps the top hottest partitions
+func (t *TableStats) summarise(top int) {
+	t.EstimatedItems = int(math.Round(float64(t.Items) * 100 / t.SamplePercent))
+
+	t.P50Size = min(t.sizes.percentile(50), t.MaxSize)
+	t.P95Size = min(t.sizes.percentile(95), t.MaxSize)
+
+	t.Attributes = sortedCounts(t.attributes)
+	t.Types = sortedCounts(t.types)
+
+	partitions := make([]PartitionStats, 0, len(t.partitions))
+	for _, partition := range t.partitions {
+		partitions = append(partitions, *partition)
+	}
+	slices.SortFunc(partitions, func(a, b PartitionStats) int {
+		return cmp.Or(cmp.Compare(b.Items, a.Items), cmp.Compare(b.Size, a.Size), cmp.Compare(a.Key, b.Key))
+	})
+	t.HotPartitions = partitions[:min(top, len(partitions))]
+}
+
+// WriteText - writes the stats as a human readable report
+func (t *TableStats) WriteText(writer io.Writer) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Table\t%s\n", t.TableName)
+	fmt.Fprintf(w, "Items scanned\t%d\n", t.Items)
+	if t.SamplePercent < 100 {
+		fmt.Fprintf(w, "Sample\t%v%%\n", t.SamplePercent)
+		fmt.Fprintf(w, "Estimated items\t%d\n", t.EstimatedItems)
+	}
+	fmt.Fprintf(w, "Total size\t%d bytes\n", t.TotalSize)
+	fmt.Fprintf(w, "Item size p50\t%d bytes\n", t.P50Size)
+	fmt.Fprintf(w, "Item size p95\t%d bytes\n", t.P95Size)
+	fmt.Fprintf(w, "Item size max\t%d bytes\n", t.MaxSize)
+	fmt.Fprintf(w, "Duration\t%v\n", t.Duration.Round(time.Millisecond))
+
+	fmt.Fprintf(w, "\nAttribute\tItems\t%%\n")
+	for _, attribute := range t.Attributes {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\n", attribute.Name, attribute.Count, t.percent(attribute.Count))
+	}
+
+	fmt.Fprintf(w, "\nType\tAttributes\n")
+	for _, attrType := range t.Types {
+		fmt.Fprintf(w, "%s\t%d\n", attrType.Name, attrType.Count)
+	}
+
+	fmt.Fprintf(w, "\nPartition\tItems\tSize\n")
+	for _, partition := range t.HotPartitions {
+		fmt.Fprintf(w, "%s\t%d\t%d bytes\n", partition.Key, partition.Items, partition.Size)
+	}
+
+	return w.Flush()
+}
+
+// percent - returns the count as a percentage of the items scanned
+func (t *TableStats) percent(count int) float64 {
+	if t.Items == 0 {
+		return 0
+	}
+
+	return float64(count) * 100 / float64(t.Items)
+}
+
+// sizeHistogram - counts item sizes in fixed width buckets so memory stays bounded however large the table is.
+// Sizes below exactItemSize have a bucket each, larger sizes share buckets of itemSizeBucket bytes.
+type sizeHistogram struct {
+	counts []int
+	total  int
+}
+
+// newSizeHistogram - creates an empty histogram with buckets up to the largest item size
+func newSizeHistogram() *sizeHistogram {
+	return &sizeHistogram{
+		counts: make([]int, sizeBucket(maxItemSize)+1),
+	}
+}
+
+// add - counts a size in its bucket
+func (h *sizeHistogram) add(size int) {
+	h.counts[sizeBucket(size)]++
+	h.total++
+}
+
+// percentile - returns the nearest rank percentile, sizes within a shared bucket are reported as the largest size of the bucket
+func (h *sizeHistogram) percentile(p float64) int {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := max(int(math.Ceil(p/100*float64(h.total))), 1)
+	seen := 0
+	for bucket, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return bucketSize(bucket)
+		}
+	}
+
+	return maxItemSize
+}
+
+// sizeBucket - returns the bucket a size is counted in
+func sizeBucket(size int) int {
+	size = min(max(size, 0), maxItemSize)
+	if size < exactItemSize {
+		return size
+	}
+
+	return exactItemSize + (size-exactItemSize)/itemSizeBucket
+}
+
+// bucketSize - returns the largest size counted in a bucket
+func bucketSize(bucket int) int {
+	if bucket < exactItemSize {
+		return bucket
+	}
+
+	return min(exactItemSize+(bucket-exactItemSize+1)*itemSizeBucket-1, maxItemSize)
+}
+
+// sortedCounts - returns the counts sorted from most to least frequent, then by name
+func sortedCounts(counts map[string]int) []StatCount {
+	sorted := make([]StatCount, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, StatCount{Name: name, Count: count})
+	}
+
+	slices.SortFunc(sorted, func(a, b StatCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Name, b.Name))
+	})
+
+	return sorted
+}
+
+// partitionValue - returns a partition key value as text, binary values are base64 encoded
+func partitionValue(value any) string {
+	switch v := value.(type) {
+	case ddb.AVString:
+		return v.S
+	case ddb.AVNumber:
+		return v.N
+	case ddb.AVByte:
+		return base64.StdEncoding.EncodeToString(v.B)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// avType - returns the dynamodb type of a converted attribute value
+func avType(value any) string {
+	switch value.(type) {
+	case ddb.AVString:
+		return "S"
+	case ddb.AVNumber:
+		return "N"
+	case ddb.AVByte:
+		return "B"
+	case ddb.AVBool:
+		return "BOOL"
+	case ddb.AVNull:
+		return "NULL"
+	case ddb.AVMap:
+		return "M"
+	case ddb.AVList:
+		return "L"
+	case ddb.AVStringSet:
+		return "SS"
+	case ddb.AVNumberSet:
+		return "NS"
+	case ddb.AVByteSet:
+		return "BS"
+	}
+
+	return "unknown"
+}
+
+// avSize - returns the size in bytes of a converted attribute value, following the dynamodb item size rules.
+// Maps and lists have 3 bytes of overhead, and 1 byte per element.
+func avSize(value any) int {
+	switch v := value.(type) {
+	case ddb.AVString:
+		return len(v.S)
+	case ddb.AVNumber:
+		return numberSize(v.N)
+	case ddb.AVByte:
+		return len(v.B)
+	case ddb.AVBool, ddb.AVNull:
+		return 1
+	case ddb.AVMap:
+		size := 3
+		for name, element := range v.M {
+			size += 1 + len(name) + avSize(element)
+		}
+		return size
+	case ddb.AVList:
+		size := 3
+		for _, element := range v.L {
+			size += 1 + avSize(element)
+		}
+		return size
+	case ddb.AVStringSet:
+		size := 0
+		for _, element := range v.SS {
+			size += len(element)
+		}
+		return size
+	case ddb.AVNumberSet:
+		size := 0
+		for _, element := range v.NS {
+			size += numberSize(element)
+		}
+		return size
+	case ddb.AVByteSet:
+		size := 0
+		for _, element := range v.BS {
+			size += len(element)
+		}
+		return size
+	}
+
+	return 0
+}
+
+// numberSize - returns the size of a number, 1 byte per two significant digits plus 1 byte
+func numberSize(number string) int {
+	mantissa, _, _ := strings.Cut(strings.ToLower(number), "e")
+	digits := strings.TrimLeft(strings.NewReplacer("-", "", "+", "", ".", "").Replace(mantissa), "0")
+	digits = strings.TrimRight(digits, "0")
+
+	return (len(digits)+1)/2 + 1
+}
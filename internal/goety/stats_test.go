@@ -0,0 +1,205 @@
+package goety
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/goety/internal/logging"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestService_Stats(t *testing.T) {
+	var client DynamoClientMock
+	var service Service
+	var mx sync.Mutex
+	var segments []int32
+	logger := logging.New(true)
+	ctx := logging.WithContext(context.Background(), logger)
+
+	items := []map[string]types.AttributeValue{
+		{
+			"pk":     &types.AttributeValueMemberS{Value: "tenant-1"},
+			"sk":     &types.AttributeValueMemberS{Value: "a"},
+			"amount": &types.AttributeValueMemberN{Value: "100"},
+		},
+		{
+			"pk": &types.AttributeValueMemberS{Value: "tenant-1"},
+			"sk": &types.AttributeValueMemberS{Value: "b"},
+		},
+		{
+			"pk":   &types.AttributeValueMemberS{Value: "tenant-2"},
+			"sk":   &types.AttributeValueMemberS{Value: "a"},
+			"tags": &types.AttributeValueMemberSS{Value: []string{"x", "yz"}},
+		},
+	}
+
+	group := odize.NewGroup(t, nil)
+
+	group.BeforeEach(func() {
+		segments = nil
+		client = DynamoClientMock{
+			ScanFunc: func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				mx.Lock()
+				defer mx.Unlock()
+
+				if input.Segment != nil {
+					segments = append(segments, *input.Segment)
+				}
+				return &dynamodb.ScanOutput{Items: items}, nil
+			},
+			DescribeTableFunc: func(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				return describeTableOutput("pk", "sk"), nil
+			},
+		}
+
+		service = Service{
+			client: &client,
+			logger: logger,
+			emitter: &mockEmitter{
+				publishFunc: func(message string) {},
+			},
+		}
+	})
+
+	err := group.
+		Test("should count items and attributes", func(t *testing.T) {
+			stats, err := service.Stats(ctx, "my-table")
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 3, stats.Items)
+			odize.AssertEqual(t, 3, stats.EstimatedItems)
+			odize.AssertEqual(t, []StatCount{{Name: "pk", Count: 3}, {Name: "sk", Count: 3}, {Name: "amount", Count: 1}, {Name: "tags", Count: 1}}, stats.Attributes)
+			odize.AssertEqual(t, []StatCount{{Name: "S", Count: 6}, {Name: "N", Count: 1}, {Name: "SS", Count: 1}}, stats.Types)
+		}).
+		Test("should report item sizes", func(t *testing.T) {
+			stats, err := service.Stats(ctx, "my-table")
+			odize.AssertNoError(t, err)
+
+			// pk + tenant-1, sk + a, amount + 100 as a single significant digit
+			odize.AssertEqual(t, 10+3+8, stats.MaxSize)
+			odize.AssertEqual(t, 3, stats.sizes.total)
+			odize.AssertEqual(t, int64(54), stats.TotalSize)
+			odize.AssertEqual(t, 20, stats.P50Size)
+			odize.AssertEqual(t, 21, stats.P95Size)
+		}).
+		Test("should report the hottest partitions", func(t *testing.T) {
+			stats, err := service.Stats(ctx, "my-table", WithTopPartitions(1))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, []PartitionStats{{Key: "tenant-1", Items: 2, Size: 34}}, stats.HotPartitions)
+		}).
+		Test("should scan a sample of the segments", func(t *testing.T) {
+			stats, err := service.Stats(ctx, "my-table", WithSample(5), WithSegments(2))
+			odize.AssertNoError(t, err)
+
+			odize.AssertEqual(t, 5, len(segments))
+			odize.AssertEqual(t, float64(5), stats.SamplePercent)
+			odize.AssertEqual(t, 15, stats.Items)
+			odize.AssertEqual(t, 300, stats.EstimatedItems)
+			odize.AssertTrue(t, client.ScanCalls()[0].Input.Segment != nil)
+			odize.AssertEqual(t, int32(statsSampleSegments), aws.ToInt32(client.ScanCalls()[0].Input.TotalSegments))
+		}).
+		Test("should return error for an invalid sample", func(t *testing.T) {
+			_, err := service.Stats(ctx, "my-table", WithSample(150))
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidSample))
+		}).
+		Test("should return error if scan fails", func(t *testing.T) {
+			expectedErr := errors.New("scan error")
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				return nil, expectedErr
+			}
+
+			_, err := service.Stats(ctx, "my-table", WithSample(10))
+			odize.AssertTrue(t, errors.Is(err, expectedErr))
+		}).
+		Test("should return error if the sample is cancelled", func(t *testing.T) {
+			cancelCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			client.ScanFunc = func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+				cancel()
+				return &dynamodb.ScanOutput{Items: items, LastEvaluatedKey: items[0]}, nil
+			}
+
+			_, err := service.Stats(cancelCtx, "my-table", WithSample(10), WithSegments(2))
+			odize.AssertTrue(t, errors.Is(err, context.Canceled))
+		}).
+		Test("should write a text report", func(t *testing.T) {
+			stats, err := service.Stats(ctx, "my-table")
+			odize.AssertNoError(t, err)
+
+			buf := bytes.Buffer{}
+			odize.AssertNoError(t, stats.WriteText(&buf))
+
+			odize.AssertTrue(t, strings.Contains(buf.String(), "Items scanned  3\n"))
+			odize.AssertTrue(t, strings.Contains(buf.String(), "tenant-1   2      34 bytes\n"))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
+
+func TestItemSize(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should size numbers by significant digits", func(t *testing.T) {
+			odize.AssertEqual(t, 1, numberSize("0"))
+			odize.AssertEqual(t, 2, numberSize("100"))
+			odize.AssertEqual(t, 3, numberSize("-12.50"))
+			odize.AssertEqual(t, 4, numberSize("12345"))
+			odize.AssertEqual(t, 2, numberSize("1e10"))
+		}).
+		Test("should size maps and lists with overhead", func(t *testing.T) {
+			stats := newTableStats("my-table")
+			err := stats.add(map[string]types.AttributeValue{
+				"m": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+					"ab": &types.AttributeValueMemberBOOL{Value: true},
+				}},
+				"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberNULL{Value: true},
+				}},
+			}, "pk")
+			odize.AssertNoError(t, err)
+
+			// m: 1 + 3 + (1 + 2 + 1), l: 1 + 3 + (1 + 1)
+			odize.AssertEqual(t, 14, stats.MaxSize)
+			odize.AssertEqual(t, 1, stats.sizes.counts[14])
+			odize.AssertEqual(t, 0, len(stats.partitions))
+		}).
+		Test("should spread sample segments evenly", func(t *testing.T) {
+			odize.AssertEqual(t, []int32{0, 25, 50, 75}, sampleSegments(4))
+			odize.AssertEqual(t, []int32{0}, sampleSegments(0.1))
+		}).
+		Test("should return the nearest rank percentile", func(t *testing.T) {
+			sizes := newSizeHistogram()
+			odize.AssertEqual(t, 0, sizes.percentile(50))
+
+			for _, size := range []int{1, 2, 3, 4} {
+				sizes.add(size)
+			}
+			odize.AssertEqual(t, 2, sizes.percentile(50))
+			odize.AssertEqual(t, 4, sizes.percentile(95))
+		}).
+		Test("should bucket large sizes within a bounded histogram", func(t *testing.T) {
+			sizes := newSizeHistogram()
+			for _, size := range []int{100, 2000, 2010, maxItemSize * 2} {
+				sizes.add(size)
+			}
+
+			odize.AssertEqual(t, sizeBucket(maxItemSize)+1, len(sizes.counts))
+			odize.AssertEqual(t, 2047, sizes.percentile(50))
+			odize.AssertEqual(t, maxItemSize, sizes.percentile(95))
+			odize.AssertEqual(t, 100, sizes.percentile(25))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
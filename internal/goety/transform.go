@@ -0,0 +1,246 @@
+package goety
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
+)
+
+var (
+	ErrInvalidTransformRule = errors.New("invalid transform rule")
+)
+
+// TransformAction - the action a transform rule applies to an attribute
+type TransformAction string
+
+const (
+	// TransformRename - renames the attribute to the "to" attribute
+	TransformRename TransformAction = "rename"
+	// TransformSet - sets the attribute to a constant json value
+	TransformSet TransformAction = "set"
+	// TransformReplace - replaces matches of a regular expression in a string or string set attribute
+	TransformReplace TransformAction = "replace"
+	// TransformDelete - deletes the attribute
+	TransformDelete TransformAction = "delete"
+	// TransformHash - replaces the attribute with the salted sha256 hash of its value, as a hex string
+	TransformHash TransformAction = "hash"
+)
+
+// TransformRule - a rule applied to an attribute of each item.
+// The attribute is an attribute name, or a dotted path to an attribute of a nested map e.g. "address.email".
+type TransformRule struct {
+	Action      TransformAction `json:"action"`
+	Attribute   string          `json:"attribute"`
+	To          string          `json:"to,omitempty"`
+	Value       json.RawMessage `json:"value,omitempty"`
+	Pattern     string          `json:"pattern,omitempty"`
+	Replacement string          `json:"replacement,omitempty"`
+	Salt        string          `json:"salt,omitempty"`
+
+	pattern *regexp.Regexp
+	value   any
+}
+
+// Transform - a set of rules applied in order to each item
+type Transform struct {
+	Rules []TransformRule `json:"rules"`
+}
+
+// LoadTransform - loads transform rules from a json file
+//
+// Example:
+//
+//	{
+//	  "rules": [
+//	    {"action": "replace", "attribute": "tenantId", "pattern": "^prod-", "replacement": "staging-"},
+//	    {"action": "hash", "attribute": "email", "salt": "staging"},
+//	    {"action": "delete", "attribute": "secret"}
+//	  ]
+//	}
+func LoadTransform(path string) (*Transform, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseTransform(file)
+}
+
+// ParseTransform - parses and validates transform rules from json
+func ParseTransform(reader io.Reader) (*Transform, error) {
+	var transform Transform
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&transform); err != nil {
+		return nil, err
+	}
+
+	for i := range transform.Rules {
+		if err := transform.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+
+	return &transform, nil
+}
+
+// compile - validates the rule, compiling the pattern and value used to apply it
+func (r *TransformRule) compile() error {
+	if r.Attribute == "" {
+		return fmt.Errorf("%w: attribute is required", ErrInvalidTransformRule)
+	}
+
+	switch r.Action {
+	case TransformRename:
+		if r.To == "" {
+			return fmt.Errorf("%w: rename requires to", ErrInvalidTransformRule)
+		}
+	case TransformSet:
+		if len(r.Value) == 0 {
+			return fmt.Errorf("%w: set requires a value", ErrInvalidTransformRule)
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(string(r.Value)))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&r.value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTransformRule, err)
+		}
+
+		if _, err := attributevalue.Marshal(r.value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTransformRule, err)
+		}
+	case TransformReplace:
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTransformRule, err)
+		}
+		r.pattern = pattern
+	case TransformDelete, TransformHash:
+	default:
+		return fmt.Errorf("%w: unsupported action %q", ErrInvalidTransformRule, r.Action)
+	}
+
+	return nil
+}
+
+// Apply - applies the rules in order to the item, the item is modified in place.
+// Rules for attributes the item does not have are skipped, except set which adds the attribute.
+func (t *Transform) Apply(item map[string]types.AttributeValue) error {
+	if t == nil {
+		return nil
+	}
+
+	for _, rule := range t.Rules {
+		if err := rule.apply(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyAll - applies the rules to each item
+func (t *Transform) ApplyAll(items []map[string]types.AttributeValue) error {
+	for _, item := range items {
+		if err := t.Apply(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r TransformRule) apply(item map[string]types.AttributeValue) error {
+	if r.Action == TransformSet {
+		// marshalled for each item, so items never share a nested map or list
+		value, err := attributevalue.Marshal(r.value)
+		if err != nil {
+			return err
+		}
+		setAttribute(item, r.Attribute, value)
+		return nil
+	}
+
+	value, ok := lookupAttribute(item, r.Attribute)
+	if !ok {
+		return nil
+	}
+
+	switch r.Action {
+	case TransformRename:
+		deleteAttribute(item, r.Attribute)
+		setAttribute(item, r.To, value)
+	case TransformDelete:
+		deleteAttribute(item, r.Attribute)
+	case TransformReplace:
+		setAttribute(item, r.Attribute, r.replace(value))
+	case TransformHash:
+		hashed, err := r.hash(value)
+		if err != nil {
+			return fmt.Errorf("hash attribute %s: %w", r.Attribute, err)
+		}
+		setAttribute(item, r.Attribute, hashed)
+	}
+
+	return nil
+}
+
+// replace - replaces pattern matches in string and string set values, other values are returned as is
+func (r TransformRule) replace(value types.AttributeValue) types.AttributeValue {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: r.pattern.ReplaceAllString(v.Value, r.Replacement)}
+	case *types.AttributeValueMemberSS:
+		// members can be replaced with the same value, sets cannot hold duplicates so only the first is kept
+		replaced := make([]string, 0, len(v.Value))
+		for _, element := range v.Value {
+			member := r.pattern.ReplaceAllString(element, r.Replacement)
+			if !slices.Contains(replaced, member) {
+				replaced = append(replaced, member)
+			}
+		}
+		return &types.AttributeValueMemberSS{Value: replaced}
+	}
+
+	return value
+}
+
+// hash - hashes the text of a string or number, the bytes of a binary value or, the dynamodb json of any other value
+func (r TransformRule) hash(value types.AttributeValue) (types.AttributeValue, error) {
+	var data []byte
+
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		data = []byte(v.Value)
+	case *types.AttributeValueMemberN:
+		data = []byte(v.Value)
+	case *types.AttributeValueMemberB:
+		data = v.Value
+	default:
+		converted, err := ddb.ConvertAVValue(map[string]types.AttributeValue{"value": value})
+		if err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(converted["value"]); err != nil {
+			return nil, err
+		}
+	}
+
+	sum := sha256.Sum256(append([]byte(r.Salt), data...))
+	return &types.AttributeValueMemberS{Value: hex.EncodeToString(sum[:])}, nil
+}
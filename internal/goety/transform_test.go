@@ -0,0 +1,99 @@
+package goety
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestTransform(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	var item map[string]types.AttributeValue
+
+	group.BeforeEach(func() {
+		item = map[string]types.AttributeValue{
+			"tenantId": &types.AttributeValueMemberS{Value: "prod-123"},
+			"email":    &types.AttributeValueMemberS{Value: "person@example.com"},
+			"secret":   &types.AttributeValueMemberS{Value: "secret"},
+			"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"city": &types.AttributeValueMemberS{Value: "Brisbane"},
+			}},
+		}
+	})
+
+	parse := func(t *testing.T, rules string) *Transform {
+		transform, err := ParseTransform(strings.NewReader(rules))
+		odize.AssertNoError(t, err)
+		return transform
+	}
+
+	err := group.
+		Test("should apply rules in order", func(t *testing.T) {
+			transform := parse(t, `{"rules": [
+				{"action": "replace", "attribute": "tenantId", "pattern": "^prod-", "replacement": "staging-"},
+				{"action": "rename", "attribute": "tenantId", "to": "tenant"},
+				{"action": "delete", "attribute": "secret"},
+				{"action": "set", "attribute": "version", "value": 2}
+			]}`)
+
+			odize.AssertNoError(t, transform.Apply(item))
+
+			odize.AssertEqual(t, "staging-123", item["tenant"].(*types.AttributeValueMemberS).Value)
+			odize.AssertEqual(t, "2", item["version"].(*types.AttributeValueMemberN).Value)
+
+			_, ok := item["tenantId"]
+			odize.AssertFalse(t, ok)
+			_, ok = item["secret"]
+			odize.AssertFalse(t, ok)
+		}).
+		Test("should hash values with salt", func(t *testing.T) {
+			transform := parse(t, `{"rules": [{"action": "hash", "attribute": "email", "salt": "staging"}]}`)
+
+			odize.AssertNoError(t, transform.Apply(item))
+
+			odize.AssertEqual(t, "5048d0bf490933d76fa36fe6d6b065677d6e428d7e973bf4827f82e09448a033", item["email"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should keep replaced set members unique", func(t *testing.T) {
+			item["tags"] = &types.AttributeValueMemberSS{Value: []string{"prod-a", "prod-b", "other"}}
+			transform := parse(t, `{"rules": [{"action": "replace", "attribute": "tags", "pattern": "^prod-.*", "replacement": "prod"}]}`)
+
+			odize.AssertNoError(t, transform.Apply(item))
+
+			odize.AssertEqual(t, []string{"prod", "other"}, item["tags"].(*types.AttributeValueMemberSS).Value)
+		}).
+		Test("should apply rules to nested attributes", func(t *testing.T) {
+			transform := parse(t, `{"rules": [
+				{"action": "set", "attribute": "address.country", "value": "AU"},
+				{"action": "delete", "attribute": "address.city"}
+			]}`)
+
+			odize.AssertNoError(t, transform.Apply(item))
+
+			address := item["address"].(*types.AttributeValueMemberM).Value
+			odize.AssertEqual(t, 1, len(address))
+			odize.AssertEqual(t, "AU", address["country"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should skip rules for missing attributes", func(t *testing.T) {
+			transform := parse(t, `{"rules": [{"action": "hash", "attribute": "phone"}]}`)
+
+			odize.AssertNoError(t, transform.Apply(item))
+			odize.AssertEqual(t, 4, len(item))
+		}).
+		Test("should return error on invalid rules", func(t *testing.T) {
+			_, err := ParseTransform(strings.NewReader(`{"rules": [{"action": "upper", "attribute": "email"}]}`))
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidTransformRule))
+
+			_, err = ParseTransform(strings.NewReader(`{"rules": [{"action": "replace", "attribute": "email", "pattern": "("}]}`))
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidTransformRule))
+
+			_, err = ParseTransform(strings.NewReader(`{"rules": [{"action": "rename", "attribute": "email"}]}`))
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidTransformRule))
+		}).
+		Run()
+
+	odize.AssertNoError(t, err)
+}
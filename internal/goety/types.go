@@ -2,6 +2,7 @@ package goety
 
 import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddb "github.com/code-gorilla-au/goety/internal/dynamodb"
 	"github.com/code-gorilla-au/goety/internal/emitter"
 	"github.com/code-gorilla-au/goety/internal/logging"
 )
@@ -16,15 +17,48 @@ type Service struct {
 type TableKeys struct {
 	PartitionKey string
 	SortKey      string
+
+	partitionType types.ScalarAttributeType
+	sortType      types.ScalarAttributeType
 }
 
 type QueryOpts struct {
-	Limit                *int32
-	FilterExpression     *string
-	ProjectedExpressions *string
-	FilterNameAttributes map[string]string
-	FilterNameValues     map[string]types.AttributeValue
-	RawOutput            bool
+	Limit                  *int32
+	KeyConditionExpression *string
+	IndexName              *string
+	FilterExpression       *string
+	Where                  *Where
+	ProjectedExpressions   *string
+	Attributes             []string
+	FilterNameAttributes   map[string]string
+	FilterNameValues       map[string]types.AttributeValue
+	RawOutput              bool
+	Segments               int32
+	NumberMode             ddb.NumberMode
+	Checkpoint             CheckpointStore
+	Resume                 bool
+	Format                 Format
+	CSVNested              CSVNested
+	Transform              *Transform
+	Mask                   *MaskConfig
+	Partition              *string
+	SortCondition          *SortCondition
+	Sample                 float64
+	TopPartitions          int
+	EntityAttribute        string
 }
 
 type QueryFuncOpts = func(*QueryOpts) *QueryOpts
+
+type SeedOpts struct {
+	// RawInput - whether the input is dynamodb json, detected from the first item when nil
+	RawInput    *bool
+	Format      Format
+	ColumnTypes map[string]string
+	Transform   *Transform
+	// ContinueOnError - items that fail are added to the dead letter store instead of stopping the seed
+	ContinueOnError bool
+	DeadLetter      DeadLetterStore
+}
+
+type SeedFuncOpts = func(*SeedOpts) *SeedOpts
@@ -0,0 +1,509 @@
+package goety
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrInvalidWhere = errors.New("invalid where condition")
+)
+
+// whereNumber - matches an unquoted decimal number
+var whereNumber = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// Where - a filter condition compiled with the dynamodb expression builder.
+// Attribute names and values are aliased, so reserved words and nested paths need no escaping.
+type Where struct {
+	condition expression.ConditionBuilder
+}
+
+// ParseWhere - parses a where condition into a filter condition.
+//
+// Conditions compare an attribute path with a value using =, !=, <>, <, <=, > or >=, between ... and ..., or in (...).
+// Conditions are combined with and, or and not, and grouped with parentheses.
+// The functions attribute_exists, attribute_not_exists, begins_with, contains and size are supported.
+// Quoted values are strings, unquoted values are numbers, true, false or null when they can be, otherwise strings.
+//
+// Example:
+//
+//	ParseWhere(`status = "active" and created > 2024-01-01`)
+//	ParseWhere(`address.city in ("Brisbane", "Sydney") or not attribute_exists(deletedAt)`)
+func ParseWhere(where string) (*Where, error) {
+	tokens, err := lexWhere(where)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &whereParser{tokens: tokens}
+
+	condition, err := parser.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if !parser.done() {
+		return nil, parser.errorf("unexpected %s", parser.peek())
+	}
+
+	return &Where{condition: condition}, nil
+}
+
+// queryExpressions - the filter and projection expressions of a scan or query, with the attribute names and values they reference
+type queryExpressions struct {
+	filter     *string
+	projection *string
+	names      map[string]string
+	values     map[string]types.AttributeValue
+}
+
+// buildExpressions - builds the filter and projection expressions of the query options.
+// Attributes are projected with aliased names, a where condition is combined with any filter expression.
+func buildExpressions(queryOpts *QueryOpts) (queryExpressions, error) {
+	exprs := queryExpressions{
+		filter:     queryOpts.FilterExpression,
+		projection: queryOpts.ProjectedExpressions,
+		names:      maps.Clone(queryOpts.FilterNameAttributes),
+		values:     maps.Clone(queryOpts.FilterNameValues),
+	}
+
+	if queryOpts.Where == nil && len(queryOpts.Attributes) == 0 {
+		return exprs, nil
+	}
+
+	builder := expression.NewBuilder()
+
+	if len(queryOpts.Attributes) > 0 {
+		names := make([]expression.NameBuilder, len(queryOpts.Attributes))
+		for i, attribute := range queryOpts.Attributes {
+			names[i] = expression.Name(strings.TrimSpace(attribute))
+		}
+		builder = builder.WithProjection(expression.NamesList(names[0], names[1:]...))
+	}
+
+	if queryOpts.Where != nil {
+		builder = builder.WithFilter(queryOpts.Where.condition)
+	}
+
+	built, err := builder.Build()
+	if err != nil {
+		return exprs, fmt.Errorf("%w: %v", ErrInvalidWhere, err)
+	}
+
+	exprs.names = mergeExpressionNames(exprs.names, built.Names())
+	exprs.values = mergeExpressionValues(exprs.values, built.Values())
+
+	if len(queryOpts.Attributes) > 0 {
+		exprs.projection = built.Projection()
+	}
+
+	if queryOpts.Where != nil {
+		exprs.filter = built.Filter()
+		if queryOpts.FilterExpression != nil {
+			exprs.filter = aws.String(fmt.Sprintf("(%s) AND (%s)", *queryOpts.FilterExpression, *built.Filter()))
+		}
+	}
+
+	return exprs, nil
+}
+
+// mergeExpressionNames - merges the built names into the names, allocating the map when there are names to merge
+func mergeExpressionNames(names map[string]string, built map[string]string) map[string]string {
+	if len(built) == 0 {
+		return names
+	}
+
+	if names == nil {
+		names = map[string]string{}
+	}
+
+	maps.Copy(names, built)
+	return names
+}
+
+// mergeExpressionValues - merges the built values into the values, allocating the map when there are values to merge
+func mergeExpressionValues(values map[string]types.AttributeValue, built map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if len(built) == 0 {
+		return values
+	}
+
+	if values == nil {
+		values = map[string]types.AttributeValue{}
+	}
+
+	maps.Copy(values, built)
+	return values
+}
+
+// whereTokenKind - the kind of a where condition token
+type whereTokenKind int
+
+const (
+	whereWord whereTokenKind = iota
+	whereString
+	whereOperator
+	whereOpen
+	whereClose
+	whereComma
+)
+
+// whereToken - a token of a where condition, the position is the byte offset of the token
+type whereToken struct {
+	kind     whereTokenKind
+	text     string
+	position int
+}
+
+func (t whereToken) String() string {
+	if t.position < 0 {
+		return t.text
+	}
+
+	if t.kind == whereString {
+		return strconv.Quote(t.text)
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+// is - returns true if the token is the keyword, keywords are case insensitive
+func (t whereToken) is(keyword string) bool {
+	return t.kind == whereWord && strings.EqualFold(t.text, keyword)
+}
+
+// lexWhere - splits a where condition into tokens
+func lexWhere(where string) ([]whereToken, error) {
+	tokens := []whereToken{}
+
+	for i := 0; i < len(where); {
+		r, size := utf8.DecodeRuneInString(where[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, whereToken{kind: whereOpen, text: "(", position: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, whereToken{kind: whereClose, text: ")", position: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, whereToken{kind: whereComma, text: ",", position: i})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(where) && where[end] != '"' {
+				if where[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(where) {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidWhere, i)
+			}
+			text, err := strconv.Unquote(where[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid string at position %d: %v", ErrInvalidWhere, i, err)
+			}
+			tokens = append(tokens, whereToken{kind: whereString, text: text, position: i})
+			i = end + 1
+		case strings.ContainsRune("=!<>", r):
+			operator := where[i : i+1]
+			if i+1 < len(where) && slices.Contains([]string{"!=", "<>", "<=", ">="}, where[i:i+2]) {
+				operator = where[i : i+2]
+			}
+			if operator == "!" {
+				return nil, fmt.Errorf("%w: unexpected \"!\" at position %d", ErrInvalidWhere, i)
+			}
+			tokens = append(tokens, whereToken{kind: whereOperator, text: operator, position: i})
+			i += len(operator)
+		default:
+			end := i
+			for end < len(where) {
+				r, size := utf8.DecodeRuneInString(where[end:])
+				if unicode.IsSpace(r) || strings.ContainsRune("(),\"=!<>", r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, whereToken{kind: whereWord, text: where[i:end], position: i})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// whereParser - a recursive descent parser of where condition tokens
+type whereParser struct {
+	tokens   []whereToken
+	position int
+}
+
+func (p *whereParser) done() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *whereParser) peek() whereToken {
+	if p.done() {
+		return whereToken{kind: whereWord, text: "end of condition", position: -1}
+	}
+
+	return p.tokens[p.position]
+}
+
+func (p *whereParser) next() whereToken {
+	token := p.peek()
+	p.position++
+	return token
+}
+
+// expect - consumes the next token, returning an error if it is not of the kind
+func (p *whereParser) expect(kind whereTokenKind, description string) (whereToken, error) {
+	token := p.next()
+	if token.kind != kind || token.position < 0 {
+		return token, p.errorAt(token, "expected %s, got %s", description, token)
+	}
+
+	return token, nil
+}
+
+func (p *whereParser) errorf(format string, args ...any) error {
+	return p.errorAt(p.peek(), format, args...)
+}
+
+func (p *whereParser) errorAt(token whereToken, format string, args ...any) error {
+	if token.position < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidWhere, fmt.Sprintf(format, args...))
+	}
+
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidWhere, fmt.Sprintf(format, args...), token.position)
+}
+
+// or - parses conditions combined with or
+func (p *whereParser) or() (expression.ConditionBuilder, error) {
+	left, err := p.and()
+	if err != nil {
+		return left, err
+	}
+
+	for p.peek().is("or") {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return right, err
+		}
+		left = left.Or(right)
+	}
+
+	return left, nil
+}
+
+// and - parses conditions combined with and
+func (p *whereParser) and() (expression.ConditionBuilder, error) {
+	left, err := p.not()
+	if err != nil {
+		return left, err
+	}
+
+	for p.peek().is("and") {
+		p.next()
+		right, err := p.not()
+		if err != nil {
+			return right, err
+		}
+		left = left.And(right)
+	}
+
+	return left, nil
+}
+
+// not - parses a negated condition
+func (p *whereParser) not() (expression.ConditionBuilder, error) {
+	if !p.peek().is("not") {
+		return p.primary()
+	}
+
+	p.next()
+	condition, err := p.not()
+	if err != nil {
+		return condition, err
+	}
+
+	return condition.Not(), nil
+}
+
+// primary - parses a grouped condition, a function or a comparison
+func (p *whereParser) primary() (expression.ConditionBuilder, error) {
+	token := p.peek()
+
+	if token.kind == whereOpen {
+		p.next()
+		condition, err := p.or()
+		if err != nil {
+			return condition, err
+		}
+		if _, err = p.expect(whereClose, "\")\""); err != nil {
+			return condition, err
+		}
+		return condition, nil
+	}
+
+	if token.kind != whereWord || token.position < 0 {
+		return expression.ConditionBuilder{}, p.errorf("expected an attribute, got %s", token)
+	}
+
+	p.next()
+
+	if p.peek().kind == whereOpen {
+		return p.function(token)
+	}
+
+	return p.comparison(expression.Name(token.text))
+}
+
+// function - parses a function call, the name has been consumed
+func (p *whereParser) function(name whereToken) (expression.ConditionBuilder, error) {
+	p.next()
+
+	path, err := p.expect(whereWord, "an attribute")
+	if err != nil {
+		return expression.ConditionBuilder{}, err
+	}
+	attribute := expression.Name(path.text)
+
+	var condition expression.ConditionBuilder
+
+	switch strings.ToLower(name.text) {
+	case "attribute_exists":
+		condition = attribute.AttributeExists()
+	case "attribute_not_exists":
+		condition = attribute.AttributeNotExists()
+	case "begins_with", "contains":
+		if _, err = p.expect(whereComma, "\",\""); err != nil {
+			return condition, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return condition, err
+		}
+		if strings.EqualFold(name.text, "contains") {
+			condition = attribute.Contains(value)
+			break
+		}
+		prefix, ok := value.(*types.AttributeValueMemberS)
+		if !ok {
+			return condition, p.errorAt(name, "begins_with requires a string prefix")
+		}
+		condition = attribute.BeginsWith(prefix.Value)
+	case "size":
+		if _, err = p.expect(whereClose, "\")\""); err != nil {
+			return condition, err
+		}
+		return p.comparison(attribute.Size())
+	default:
+		return condition, p.errorAt(name, "unsupported function %s", name)
+	}
+
+	if _, err = p.expect(whereClose, "\")\""); err != nil {
+		return condition, err
+	}
+
+	return condition, nil
+}
+
+// comparison - parses the comparison of an operand with values
+func (p *whereParser) comparison(operand expression.OperandBuilder) (expression.ConditionBuilder, error) {
+	token := p.next()
+
+	switch {
+	case token.kind == whereOperator:
+		value, err := p.value()
+		if err != nil {
+			return expression.ConditionBuilder{}, err
+		}
+		right := expression.Value(value)
+
+		switch token.text {
+		case "=":
+			return expression.Equal(operand, right), nil
+		case "!=", "<>":
+			return expression.NotEqual(operand, right), nil
+		case "<":
+			return expression.LessThan(operand, right), nil
+		case "<=":
+			return expression.LessThanEqual(operand, right), nil
+		case ">":
+			return expression.GreaterThan(operand, right), nil
+		}
+		return expression.GreaterThanEqual(operand, right), nil
+	case token.is("between"):
+		lower, err := p.value()
+		if err != nil {
+			return expression.ConditionBuilder{}, err
+		}
+		if and := p.next(); !and.is("and") {
+			return expression.ConditionBuilder{}, p.errorAt(and, "expected \"and\", got %s", and)
+		}
+		upper, err := p.value()
+		if err != nil {
+			return expression.ConditionBuilder{}, err
+		}
+		return expression.Between(operand, expression.Value(lower), expression.Value(upper)), nil
+	case token.is("in"):
+		if _, err := p.expect(whereOpen, "\"(\""); err != nil {
+			return expression.ConditionBuilder{}, err
+		}
+		values := []expression.OperandBuilder{}
+		for {
+			value, err := p.value()
+			if err != nil {
+				return expression.ConditionBuilder{}, err
+			}
+			values = append(values, expression.Value(value))
+			if p.peek().kind != whereComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(whereClose, "\")\""); err != nil {
+			return expression.ConditionBuilder{}, err
+		}
+		return expression.In(operand, values[0], values[1:]...), nil
+	}
+
+	return expression.ConditionBuilder{}, p.errorAt(token, "expected a comparison, got %s", token)
+}
+
+// value - parses a value, quoted values are strings and unquoted values are numbers, booleans or null when they can be
+func (p *whereParser) value() (types.AttributeValue, error) {
+	token := p.next()
+
+	switch {
+	case token.kind == whereString:
+		return &types.AttributeValueMemberS{Value: token.text}, nil
+	case token.kind != whereWord || token.position < 0:
+		return nil, p.errorAt(token, "expected a value, got %s", token)
+	case token.is("true"), token.is("false"):
+		return &types.AttributeValueMemberBOOL{Value: token.is("true")}, nil
+	case token.is("null"):
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	}
+
+	if whereNumber.MatchString(token.text) {
+		return &types.AttributeValueMemberN{Value: token.text}, nil
+	}
+
+	return &types.AttributeValueMemberS{Value: token.text}, nil
+}
@@ -0,0 +1,120 @@
+package goety
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/code-gorilla-au/odize"
+)
+
+func TestParseWhere(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	build := func(t *testing.T, where string) queryExpressions {
+		parsed, err := ParseWhere(where)
+		odize.AssertNoError(t, err)
+
+		exprs, err := buildExpressions(&QueryOpts{Where: parsed})
+		odize.AssertNoError(t, err)
+		return exprs
+	}
+
+	err := group.
+		Test("should alias reserved words and nested paths", func(t *testing.T) {
+			exprs := build(t, `status = "active" and address.city <> "Perth"`)
+			odize.AssertEqual(t, "(#0 = :0) AND (#1.#2 <> :1)", *exprs.filter)
+			odize.AssertEqual(t, map[string]string{"#0": "status", "#1": "address", "#2": "city"}, exprs.names)
+		}).
+		Test("should type unquoted values", func(t *testing.T) {
+			exprs := build(t, `age >= 30 and active = true and deletedAt = null and created > 2024-01-01 and code = "30"`)
+			odize.AssertEqual(t, map[string]types.AttributeValue{
+				":0": &types.AttributeValueMemberN{Value: "30"},
+				":1": &types.AttributeValueMemberBOOL{Value: true},
+				":2": &types.AttributeValueMemberNULL{Value: true},
+				":3": &types.AttributeValueMemberS{Value: "2024-01-01"},
+				":4": &types.AttributeValueMemberS{Value: "30"},
+			}, exprs.values)
+		}).
+		Test("should respect precedence of not, and, or and parentheses", func(t *testing.T) {
+			exprs := build(t, `a = 1 or not b = 2 and (c = 3 or d = 4)`)
+			odize.AssertEqual(t, "(#0 = :0) OR ((NOT (#1 = :1)) AND ((#2 = :2) OR (#3 = :3)))", *exprs.filter)
+		}).
+		Test("should parse between and in", func(t *testing.T) {
+			exprs := build(t, `price BETWEEN 10 AND 20 and city in ("Brisbane", "Sydney")`)
+			odize.AssertEqual(t, "(#0 BETWEEN :0 AND :1) AND (#1 IN (:2, :3))", *exprs.filter)
+		}).
+		Test("should parse functions", func(t *testing.T) {
+			exprs := build(t, `attribute_exists(email) and attribute_not_exists(deletedAt) and begins_with(sk, "order#") and contains(tags, "vip") and size(items[0].notes) < 100`)
+			odize.AssertEqual(t, "((((attribute_exists (#0)) AND (attribute_not_exists (#1))) AND (begins_with (#2, :0))) AND (contains (#3, :1))) AND (size (#4[0].#5) < :2)", *exprs.filter)
+		}).
+		Test("should unescape quoted strings", func(t *testing.T) {
+			exprs := build(t, `note = "say \"hello\", world"`)
+			odize.AssertEqual(t, `say "hello", world`, exprs.values[":0"].(*types.AttributeValueMemberS).Value)
+		}).
+		Test("should return error for a missing value", func(t *testing.T) {
+			_, err := ParseWhere(`status =`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidWhere))
+		}).
+		Test("should return error for an unterminated string", func(t *testing.T) {
+			_, err := ParseWhere(`status = "active`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidWhere))
+		}).
+		Test("should return error for an unclosed group", func(t *testing.T) {
+			_, err := ParseWhere(`(status = "active"`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidWhere))
+		}).
+		Test("should return error for trailing tokens", func(t *testing.T) {
+			_, err := ParseWhere(`status = "active" "inactive"`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidWhere))
+		}).
+		Test("should return error for an unsupported function", func(t *testing.T) {
+			_, err := ParseWhere(`lower(status) = "active"`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidWhere))
+		}).
+		Test("should return error for begins with a number", func(t *testing.T) {
+			_, err := ParseWhere(`begins_with(sk, 10)`)
+			odize.AssertTrue(t, errors.Is(err, ErrInvalidWhere))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}
+
+func TestBuildExpressions(t *testing.T) {
+	group := odize.NewGroup(t, nil)
+
+	err := group.
+		Test("should leave options without attributes or where as is", func(t *testing.T) {
+			exprs, err := buildExpressions(&QueryOpts{FilterExpression: aws.String("#a = :a"), FilterNameAttributes: map[string]string{"#a": "a"}})
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "#a = :a", *exprs.filter)
+			odize.AssertEqual(t, map[string]string{"#a": "a"}, exprs.names)
+			odize.AssertTrue(t, exprs.values == nil)
+			odize.AssertTrue(t, exprs.projection == nil)
+		}).
+		Test("should escape projected attributes", func(t *testing.T) {
+			exprs, err := buildExpressions(&QueryOpts{Attributes: []string{"name", "address.city"}})
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "#0, #1.#2", *exprs.projection)
+			odize.AssertEqual(t, map[string]string{"#0": "name", "#1": "address", "#2": "city"}, exprs.names)
+			odize.AssertTrue(t, exprs.filter == nil)
+		}).
+		Test("should combine the where condition with the filter expression", func(t *testing.T) {
+			where, err := ParseWhere(`status = "active"`)
+			odize.AssertNoError(t, err)
+
+			exprs, err := buildExpressions(&QueryOpts{
+				Where:                where,
+				FilterExpression:     aws.String("#tenant = :tenant"),
+				FilterNameAttributes: map[string]string{"#tenant": "tenant"},
+				FilterNameValues:     map[string]types.AttributeValue{":tenant": &types.AttributeValueMemberS{Value: "tenant-1"}},
+			})
+			odize.AssertNoError(t, err)
+			odize.AssertEqual(t, "(#tenant = :tenant) AND (#0 = :0)", *exprs.filter)
+			odize.AssertEqual(t, map[string]string{"#tenant": "tenant", "#0": "status"}, exprs.names)
+			odize.AssertEqual(t, 2, len(exprs.values))
+		}).
+		Run()
+	odize.AssertNoError(t, err)
+}